@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// activeTickMsg refreshes the elapsed time of active tasks once a second.
+type activeTickMsg struct{}
+
+func activeTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return activeTickMsg{}
+	})
+}
+
+// toggleStart starts the selected task, or stops it if it is active, as
+// `task start` and `task stop` do.
+func (m *App) toggleStart() tea.Cmd {
+	selected, ok := m.selectedTodo()
+	if !ok || selected.completed || selected.recurring {
+		return nil
+	}
+
+	if selected.start != 0 {
+		selected.start = 0
+	} else {
+		selected.start = time.Now().Unix()
+	}
+	cmd := m.updateTodo(selected)
+	// Starting moves the task to the top, so keep the cursor on it
+	m.selectTodo(selected.uuid)
+	return tea.Batch(cmd, m.startActiveTick())
+}
+
+// activeTodos returns the tasks being worked on, longest running first.
+func (m *App) activeTodos() []todo {
+	var active []todo
+	for _, t := range m.todos {
+		if t.start != 0 && !t.completed {
+			active = append(active, t)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].start < active[j].start
+	})
+	return active
+}
+
+// startActiveTick begins the elapsed-time counter unless it is already
+// running or nothing is active.
+func (m *App) startActiveTick() tea.Cmd {
+	if m.ticking || len(m.activeTodos()) == 0 {
+		return nil
+	}
+	m.ticking = true
+	return activeTick()
+}
+
+func (m *App) handleActiveTick() tea.Cmd {
+	if len(m.activeTodos()) == 0 {
+		m.ticking = false
+		return nil
+	}
+
+	m.updateTable()
+	return activeTick()
+}
+
+// activeFirst moves active tasks to the top, keeping the order otherwise.
+func activeFirst(todos []todo) {
+	sort.SliceStable(todos, func(i, j int) bool {
+		return todos[i].start != 0 && !todos[i].completed && (todos[j].start == 0 || todos[j].completed)
+	})
+}
+
+// formatElapsed renders the time since start as 4:05 under an hour and
+// 1h05m after.
+func formatElapsed(start int64, now time.Time) string {
+	elapsed := max(now.Sub(time.Unix(start, 0)), 0)
+	if elapsed < time.Hour {
+		return fmt.Sprintf("%d:%02d", int(elapsed.Minutes()), int(elapsed.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(elapsed.Hours()), int(elapsed.Minutes())%60)
+}
+
+// activeColor is the colour of a row being worked on.
+var activeColor = lipgloss.Color("#a6e3a1")
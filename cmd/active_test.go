@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/EwanGreer/todolist/timelog"
+)
+
+func TestFormatElapsed(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	for _, tc := range []struct {
+		elapsed time.Duration
+		want    string
+	}{
+		{0, "0:00"},
+		{-time.Minute, "0:00"},
+		{4*time.Minute + 5*time.Second, "4:05"},
+		{59*time.Minute + 59*time.Second, "59:59"},
+		{time.Hour + 5*time.Minute, "1h05m"},
+		{26 * time.Hour, "26h00m"},
+	} {
+		if got := formatElapsed(now.Add(-tc.elapsed).Unix(), now); got != tc.want {
+			t.Errorf("formatElapsed(%v) = %q, want %q", tc.elapsed, got, tc.want)
+		}
+	}
+}
+
+func TestActiveFirst(t *testing.T) {
+	todos := []todo{{uuid: "a"}, {uuid: "b", start: 1}, {uuid: "c"}, {uuid: "d", start: 2, completed: true}, {uuid: "e", start: 3}}
+	activeFirst(todos)
+
+	var got []string
+	for _, t := range todos {
+		got = append(got, t.uuid)
+	}
+	if want := []string{"b", "e", "a", "c", "d"}; !slices.Equal(got, want) {
+		t.Errorf("order %q, want %q", got, want)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	dir := t.TempDir()
+	log := timelog.New(dir)
+	h := newHarness(t, WithTimeLog(log))
+	added := h.addTask("Write report project:work")
+
+	h.press("s")
+	h.settle()
+	task := h.stored(added.uuid)
+	if task.Start == 0 {
+		t.Fatal("s did not start the task")
+	}
+	if active := h.app.activeTodos(); len(active) != 1 || active[0].uuid != added.uuid {
+		t.Errorf("active tasks %v, want the started one", uuidsOf(active))
+	}
+
+	// Backdate the start so the stop records a measurable interval
+	i := h.app.todoIndex(added.uuid)
+	h.app.todos[i].start = time.Now().Add(-time.Hour).Unix()
+	h.press("s")
+	h.settle()
+
+	if task := h.stored(added.uuid); task.Start != 0 {
+		t.Errorf("s did not stop the task: start %d", task.Start)
+	}
+	entries, err := log.Entries()
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].UUID != added.uuid || entries[0].Project != "work" {
+		t.Fatalf("logged %+v, want one entry for the task", entries)
+	}
+	if d := entries[0].Duration(); d < 59*time.Minute || d > 61*time.Minute {
+		t.Errorf("logged %v, want about an hour", d)
+	}
+}
@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type bulkInputKind int
+
+const (
+	bulkMove bulkInputKind = iota
+	bulkTag
+)
+
+// toggleMark marks or unmarks the row under the cursor and moves down, so
+// consecutive rows can be marked by holding the key.
+func (m *App) toggleMark() {
+	selected, ok := m.selectedTodo()
+	if !ok {
+		return
+	}
+
+	if _, marked := m.selected[selected.uuid]; marked {
+		delete(m.selected, selected.uuid)
+	} else {
+		m.selected[selected.uuid] = struct{}{}
+	}
+
+	m.table.MoveDown(1)
+	m.updateTable()
+}
+
+// toggleVisual starts a range selection anchored at the cursor, or ends one
+// by marking every row in the range.
+func (m *App) toggleVisual() {
+	if !m.visualMode {
+		m.visualMode = true
+		m.visualAnchor = m.table.Cursor()
+		m.updateTable()
+		return
+	}
+
+	for uuid := range m.visualRange() {
+		m.selected[uuid] = struct{}{}
+	}
+	m.visualMode = false
+	m.updateTable()
+}
+
+// visualRange returns the UUIDs between the visual anchor and the cursor.
+func (m *App) visualRange() map[string]struct{} {
+	inRange := make(map[string]struct{})
+	if !m.visualMode {
+		return inRange
+	}
+
+	start, end := m.visualAnchor, m.table.Cursor()
+	if start > end {
+		start, end = end, start
+	}
+
+	filtered := m.getFilteredTodos()
+	for i := start; i <= end && i < len(filtered); i++ {
+		inRange[filtered[i].uuid] = struct{}{}
+	}
+	return inRange
+}
+
+func (m *App) isMarked(uuid string) bool {
+	if _, ok := m.selected[uuid]; ok {
+		return true
+	}
+	_, ok := m.visualRange()[uuid]
+	return ok
+}
+
+func (m *App) hasMarks() bool {
+	return len(m.selected) > 0 || m.visualMode
+}
+
+// markedTodos returns the marked rows in display order, falling back to the
+// row under the cursor when nothing is marked.
+func (m *App) markedTodos() []todo {
+	if !m.hasMarks() {
+		if selected, ok := m.selectedTodo(); ok {
+			return []todo{selected}
+		}
+		return nil
+	}
+
+	inRange := m.visualRange()
+	var marked []todo
+	for _, t := range m.getFilteredTodos() {
+		_, isSelected := m.selected[t.uuid]
+		_, isInRange := inRange[t.uuid]
+		if isSelected || isInRange {
+			marked = append(marked, t)
+		}
+	}
+	return marked
+}
+
+func (m *App) clearMarks() {
+	m.selected = make(map[string]struct{})
+	m.visualMode = false
+	m.updateTable()
+}
+
+func (m *App) bulkComplete() tea.Cmd {
+	todos := completable(m.markedTodos())
+	return m.confirmBulk(fmt.Sprintf("Complete %s?", pluralTasks(len(todos))), todos, func() tea.Cmd {
+		return m.completeTodos(todos, "completed")
+	})
+}
+
+// completable returns the open tasks in todos marked completed. Templates
+// are not done themselves; their instances are.
+func completable(todos []todo) []todo {
+	var open []todo
+	for _, t := range todos {
+		if t.completed || t.recurring {
+			continue
+		}
+		t.completed = true
+		open = append(open, t)
+	}
+	return open
+}
+
+// completeTodos saves todos, already marked completed, as one batch.
+func (m *App) completeTodos(todos []todo, verb string) tea.Cmd {
+	var instances []todo
+	for _, t := range todos {
+		if t.parent != "" {
+			instances = append(instances, t)
+		}
+	}
+
+	return m.confirmComplete(todos, func() tea.Cmd {
+		if len(instances) == 0 {
+			return m.updateTodos(todos, writeOpts{verb: verb})
+		}
+		// Completing an instance also updates its template and may
+		// generate the next one, all in the same batch
+		batch, err := m.completionBatch(instances)
+		if err != nil {
+			return m.notifyError(fmt.Errorf("could not save task: %w", err))
+		}
+		for _, t := range todos {
+			if t.parent == "" {
+				batch = append(batch, t)
+			}
+		}
+		return m.saveTodos(batch, writeOpts{verb: verb})
+	})
+}
+
+func (m *App) bulkDelete() tea.Cmd {
+	todos := m.markedTodos()
+	return m.confirmBulk(fmt.Sprintf("Delete %s?", pluralTasks(len(todos))), todos, func() tea.Cmd {
+		return m.deleteTodos(todos, writeOpts{verb: "deleted"})
+	})
+}
+
+// confirmBulk asks before applying action to todos, and clears the marks
+// only once it goes ahead so a cancelled action keeps them.
+func (m *App) confirmBulk(prompt string, todos []todo, apply func() tea.Cmd) tea.Cmd {
+	return m.confirm(prompt, todos, func() tea.Cmd {
+		m.clearMarks()
+		return apply()
+	})
+}
+
+func (m *App) startBulkInput(kind bulkInputKind) {
+	if len(m.markedTodos()) == 0 {
+		return
+	}
+	m.bulkInputMode = true
+	m.bulkInputKind = kind
+	m.bulkInputText = ""
+}
+
+func (m *App) handleBulkInputKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		var cmd tea.Cmd
+		if text := strings.TrimSpace(m.bulkInputText); text != "" {
+			switch m.bulkInputKind {
+			case bulkMove:
+				cmd = m.bulkMove(text)
+			case bulkTag:
+				cmd = m.bulkTag(text)
+			}
+		}
+		m.bulkInputMode = false
+		m.bulkInputText = ""
+		return cmd
+	case "esc":
+		m.bulkInputMode = false
+		m.bulkInputText = ""
+		return nil
+	case "backspace":
+		if len(m.bulkInputText) > 0 {
+			m.bulkInputText = m.bulkInputText[:len(m.bulkInputText)-1]
+		}
+		return nil
+	case "ctrl+c":
+		return m.quit()
+	default:
+		if len(msg.String()) == 1 {
+			m.bulkInputText += msg.String()
+		}
+		return nil
+	}
+}
+
+func (m *App) bulkMove(project string) tea.Cmd {
+	var todos []todo
+	for _, t := range m.markedTodos() {
+		t.project = project
+		todos = append(todos, t)
+	}
+	return m.confirmBulk(fmt.Sprintf("Move %s to %s?", pluralTasks(len(todos)), project), todos, func() tea.Cmd {
+		return m.updateTodos(todos, writeOpts{verb: "moved"})
+	})
+}
+
+func (m *App) bulkTag(input string) tea.Cmd {
+	tags := strings.Fields(strings.ReplaceAll(input, "+", " "))
+	if len(tags) == 0 {
+		return nil
+	}
+
+	var todos []todo
+	for _, t := range m.markedTodos() {
+		updated := append([]string(nil), t.tags...)
+		for _, tag := range tags {
+			if !containsString(updated, tag) {
+				updated = append(updated, tag)
+			}
+		}
+		t.tags = updated
+		todos = append(todos, t)
+	}
+	return m.confirmBulk(fmt.Sprintf("Tag %s with +%s?", pluralTasks(len(todos)), strings.Join(tags, " +")), todos, func() tea.Cmd {
+		return m.updateTodos(todos, writeOpts{verb: "tagged"})
+	})
+}
+
+func containsString(items []string, item string) bool {
+	for _, existing := range items {
+		if existing == item {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *App) renderBulkInput() string {
+	heading, hint := "Move Marked Tasks", "Enter the project to move marked tasks to"
+	if m.bulkInputKind == bulkTag {
+		heading, hint = "Tag Marked Tasks", "Enter tags to add, separated by spaces"
+	}
+
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#fab387")).
+		Bold(true).
+		Render(heading)
+
+	inputStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#fab387")).
+		Padding(0, 1).
+		Width(50)
+
+	inputField := inputStyle.Render(m.bulkInputText + "_")
+
+	instructions := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086")).
+		Render(hint + " • enter to apply • esc to cancel")
+
+	style := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#6c7086")).
+		Padding(1, 2).
+		Width(60)
+
+	return style.Render(title + "\n\n" + inputField + "\n\n" + instructions)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+// addTasks adds a task for each text and returns them in the order added.
+func (h *appHarness) addTasks(texts ...string) []todo {
+	h.t.Helper()
+	var added []todo
+	for _, text := range texts {
+		added = append(added, h.addTask(text))
+	}
+	return added
+}
+
+func uuidsOf(todos []todo) []string {
+	uuids := make([]string, len(todos))
+	for i, t := range todos {
+		uuids[i] = t.uuid
+	}
+	slices.Sort(uuids)
+	return uuids
+}
+
+func TestMarkedTodos(t *testing.T) {
+	h := newHarness(t)
+	h.addTasks("one", "two", "three", "four")
+	rows := h.app.getFilteredTodos()
+
+	if marked := h.app.markedTodos(); len(marked) != 1 || marked[0].uuid != rows[0].uuid {
+		t.Errorf("with nothing marked got %v, want the row under the cursor", uuidsOf(marked))
+	}
+
+	// m marks and moves down, so this marks the first and third rows
+	h.press("m", "j", "m")
+	if got, want := uuidsOf(h.app.markedTodos()), uuidsOf([]todo{rows[0], rows[2]}); !slices.Equal(got, want) {
+		t.Errorf("marked %v, want %v", got, want)
+	}
+
+	h.press("esc")
+	if h.app.hasMarks() {
+		t.Error("esc kept the marks")
+	}
+}
+
+func TestVisualRange(t *testing.T) {
+	h := newHarness(t)
+	h.addTasks("one", "two", "three", "four")
+	rows := h.app.getFilteredTodos()
+
+	h.press("j", "V", "j", "j")
+	want := uuidsOf(rows[1:4])
+	if got := uuidsOf(h.app.markedTodos()); !slices.Equal(got, want) {
+		t.Errorf("visual range marked %v, want %v", got, want)
+	}
+
+	// Ending the range keeps its rows marked
+	h.press("V")
+	if h.app.visualMode {
+		t.Error("V did not end the range")
+	}
+	if got := uuidsOf(h.app.markedTodos()); !slices.Equal(got, want) {
+		t.Errorf("after ending the range marked %v, want %v", got, want)
+	}
+}
+
+func TestBulkActions(t *testing.T) {
+	h := newHarness(t, WithoutConfirmation())
+	added := h.addTasks("one", "two", "three")
+	marked := added[:2]
+	markAll := func() {
+		for _, row := range marked {
+			h.app.selected[row.uuid] = struct{}{}
+		}
+	}
+
+	markAll()
+	h.press("M")
+	h.typeText("home")
+	h.press("enter")
+	h.settle()
+	for _, row := range marked {
+		if task := h.stored(row.uuid); task.Project != "home" {
+			t.Errorf("%s moved to %q, want home", row.text, task.Project)
+		}
+	}
+	if h.app.hasMarks() {
+		t.Error("marks kept after the move")
+	}
+
+	markAll()
+	h.press("T")
+	h.typeText("+errand +later")
+	h.press("enter")
+	h.settle()
+	for _, row := range marked {
+		if task := h.stored(row.uuid); !task.HasTag("errand") || !task.HasTag("later") {
+			t.Errorf("%s tagged %q, want errand and later", row.text, task.Tags)
+		}
+	}
+
+	markAll()
+	h.press("c")
+	h.settle()
+	for _, row := range marked {
+		if task := h.stored(row.uuid); task.Status != "completed" {
+			t.Errorf("%s status %q, want completed", row.text, task.Status)
+		}
+	}
+	if task := h.stored(added[2].uuid); task.Status != "pending" {
+		t.Errorf("unmarked task status %q, want pending", task.Status)
+	}
+
+	// The whole bulk action is one undo
+	h.press("u")
+	h.settle()
+	for _, row := range marked {
+		if task := h.stored(row.uuid); task.Status != "pending" {
+			t.Errorf("%s status %q after undo, want pending", row.text, task.Status)
+		}
+	}
+
+	markAll()
+	h.press("d")
+	h.settle()
+	for _, row := range marked {
+		if task := h.stored(row.uuid); task.Status != "deleted" {
+			t.Errorf("%s status %q, want deleted", row.text, task.Status)
+		}
+	}
+	if len(h.app.todos) != 1 {
+		t.Errorf("%d tasks listed after deleting two of three", len(h.app.todos))
+	}
+}
+
+func TestBulkActionSummary(t *testing.T) {
+	h := newHarness(t, WithoutConfirmation())
+	for _, row := range h.addTasks("one", "two") {
+		h.app.selected[row.uuid] = struct{}{}
+	}
+
+	h.press("c")
+	h.settle()
+	if h.app.status == nil || h.app.status.severity != severitySuccess || h.app.status.text != "completed 2 tasks" {
+		t.Errorf("status %+v, want a summary of 2 completed tasks", h.app.status)
+	}
+}
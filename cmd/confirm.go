@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxConfirmItems bounds how many task descriptions the dialog lists.
+const maxConfirmItems = 5
+
+// confirmDialog asks before running a destructive action. items are the
+// descriptions of the tasks the action touches.
+type confirmDialog struct {
+	prompt    string
+	items     []string
+	onConfirm func() tea.Cmd
+}
+
+// confirm runs action straight away when confirmations are turned off, and
+// otherwise opens a dialog listing todos that runs it on y.
+func (m *App) confirm(prompt string, todos []todo, action func() tea.Cmd) tea.Cmd {
+	items := make([]string, len(todos))
+	for i, t := range todos {
+		items[i] = t.text
+	}
+	return m.confirmItems(prompt, items, action)
+}
+
+// confirmItems is confirm for things other than whole tasks, listing items
+// in the dialog instead.
+func (m *App) confirmItems(prompt string, items []string, action func() tea.Cmd) tea.Cmd {
+	if len(items) == 0 {
+		return nil
+	}
+	if m.skipConfirm {
+		return action()
+	}
+
+	m.confirmDialog = &confirmDialog{prompt: prompt, items: items, onConfirm: action}
+	return nil
+}
+
+func (m *App) handleConfirmKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		action := m.confirmDialog.onConfirm
+		m.confirmDialog = nil
+		return action()
+	case "n", "N", "esc", "q":
+		m.confirmDialog = nil
+		return nil
+	case "ctrl+c":
+		return m.quit()
+	}
+	return nil
+}
+
+func (m *App) renderConfirmDialog() string {
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#f38ba8")).
+		Bold(true).
+		Render(m.confirmDialog.prompt)
+
+	itemStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#cdd6f4"))
+
+	var items []string
+	for i, item := range m.confirmDialog.items {
+		if i == maxConfirmItems {
+			items = append(items, itemStyle.Render(fmt.Sprintf("  … and %d more", len(m.confirmDialog.items)-i)))
+			break
+		}
+		items = append(items, itemStyle.Render("  • "+item))
+	}
+
+	instructions := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086")).
+		Render("y to confirm • n/esc to cancel")
+
+	style := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#f38ba8")).
+		Padding(1, 2).
+		Width(60)
+
+	return style.Render(title + "\n\n" + strings.Join(items, "\n") + "\n\n" + instructions)
+}
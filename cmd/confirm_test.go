@@ -0,0 +1,81 @@
+package cmd
+
+import "testing"
+
+func TestConfirmDelete(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		answer string
+		want   string
+	}{
+		{"y deletes", "y", "deleted"},
+		{"enter deletes", "enter", "deleted"},
+		{"n keeps", "n", "pending"},
+		{"esc keeps", "esc", "pending"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newHarness(t)
+			added := h.addTask("Keep me")
+
+			h.press("d")
+			if h.app.confirmDialog == nil {
+				t.Fatal("d deleted without asking")
+			}
+			if items := h.app.confirmDialog.items; len(items) != 1 || items[0] != "Keep me" {
+				t.Errorf("dialog lists %q, want the task", items)
+			}
+			if task := h.stored(added.uuid); task.Status != "pending" {
+				t.Fatalf("status %q while asking, want pending", task.Status)
+			}
+
+			h.press(tc.answer)
+			h.settle()
+			if h.app.confirmDialog != nil {
+				t.Error("dialog still open after answering")
+			}
+			if task := h.stored(added.uuid); task.Status != tc.want {
+				t.Errorf("status %q, want %s", task.Status, tc.want)
+			}
+		})
+	}
+}
+
+func TestConfirmIgnoresOtherKeys(t *testing.T) {
+	h := newHarness(t)
+	h.addTask("Keep me")
+
+	h.press("d", "j", "a", "d")
+	if h.app.confirmDialog == nil || h.app.addMode {
+		t.Error("keys other than y and n reached the table while asking")
+	}
+}
+
+func TestConfirmKeepsMarksWhenCancelled(t *testing.T) {
+	h := newHarness(t)
+	for _, row := range h.addTasks("one", "two") {
+		h.app.selected[row.uuid] = struct{}{}
+	}
+
+	h.press("d")
+	if h.app.confirmDialog == nil || h.app.confirmDialog.prompt != "Delete 2 tasks?" {
+		t.Fatalf("dialog %+v, want one for 2 tasks", h.app.confirmDialog)
+	}
+	h.press("n")
+	if len(h.app.selected) != 2 {
+		t.Errorf("%d tasks marked after cancelling, want 2", len(h.app.selected))
+	}
+}
+
+func TestWithoutConfirmation(t *testing.T) {
+	h := newHarness(t, WithoutConfirmation())
+	added := h.addTask("Go away")
+
+	h.press("d")
+	h.settle()
+	if h.app.confirmDialog != nil {
+		t.Error("asked with confirmations turned off")
+	}
+	if task := h.stored(added.uuid); task.Status != "deleted" {
+		t.Errorf("status %q, want deleted", task.Status)
+	}
+}
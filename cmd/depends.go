@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// blockers returns the open tasks t depends on. Dependencies that are done,
+// deleted or not loaded no longer block.
+func (m *App) blockers(t todo) []todo {
+	var open []todo
+	for _, uuid := range t.depends {
+		if i := m.todoIndex(uuid); i >= 0 && !m.todos[i].completed {
+			open = append(open, m.todos[i])
+		}
+	}
+	return open
+}
+
+// blockingSet returns the UUIDs of tasks that an open task depends on.
+func (m *App) blockingSet() map[string]bool {
+	blocking := make(map[string]bool)
+	for _, t := range m.todos {
+		if t.completed {
+			continue
+		}
+		for _, uuid := range t.depends {
+			blocking[uuid] = true
+		}
+	}
+	return blocking
+}
+
+// dependents returns the tasks that depend on uuid.
+func (m *App) dependents(uuid string) []todo {
+	var dependents []todo
+	for _, t := range m.todos {
+		if containsString(t.depends, uuid) {
+			dependents = append(dependents, t)
+		}
+	}
+	sortTodosByCreatedAt(dependents)
+	return dependents
+}
+
+// dependsOn reports whether from depends on target, directly or through
+// other tasks.
+func (m *App) dependsOn(from, target string, seen map[string]bool) bool {
+	if seen[from] {
+		return false
+	}
+	seen[from] = true
+
+	i := m.todoIndex(from)
+	if i < 0 {
+		return false
+	}
+	for _, uuid := range m.todos[i].depends {
+		if uuid == target || m.dependsOn(uuid, target, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// confirmComplete runs action, which completes todos, asking first if any
+// of them are still blocked by open tasks.
+func (m *App) confirmComplete(todos []todo, action func() tea.Cmd) tea.Cmd {
+	var open []todo
+	seen := make(map[string]bool)
+	for _, t := range todos {
+		if !t.completed {
+			continue
+		}
+		for _, blocker := range m.blockers(t) {
+			if !seen[blocker.uuid] {
+				seen[blocker.uuid] = true
+				open = append(open, blocker)
+			}
+		}
+	}
+	if len(open) == 0 {
+		return action()
+	}
+
+	prompt := fmt.Sprintf("Blocked by %s still open. Complete anyway?", pluralTasks(len(open)))
+	return m.confirm(prompt, open, action)
+}
+
+func (m *App) startDependsSelection() {
+	selected, ok := m.selectedTodo()
+	if !ok || m.isPending(selected.uuid) {
+		return
+	}
+
+	m.dependsMode = true
+	m.dependsUUID = selected.uuid
+	m.dependsCursor = 0
+	m.dependsChoices = make(map[string]bool)
+	for _, uuid := range selected.depends {
+		m.dependsChoices[uuid] = true
+	}
+
+	// Open tasks can be linked; finished ones are only listed to be unlinked
+	m.dependsOptions = nil
+	for _, t := range m.todos {
+		if t.uuid != selected.uuid && (!t.completed || m.dependsChoices[t.uuid]) {
+			m.dependsOptions = append(m.dependsOptions, t)
+		}
+	}
+	sortTodosByCreatedAt(m.dependsOptions)
+}
+
+func (m *App) handleDependsSelectionKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		if m.dependsCursor > 0 {
+			m.dependsCursor--
+		}
+	case "down", "j":
+		if m.dependsCursor < len(m.dependsOptions)-1 {
+			m.dependsCursor++
+		}
+	case " ":
+		if m.dependsCursor < len(m.dependsOptions) {
+			uuid := m.dependsOptions[m.dependsCursor].uuid
+			m.dependsChoices[uuid] = !m.dependsChoices[uuid]
+		}
+	case "enter":
+		m.dependsMode = false
+		return m.applyDepends()
+	case "esc":
+		m.dependsMode = false
+	case "ctrl+c", "q":
+		return m.quit()
+	}
+	return nil
+}
+
+func (m *App) applyDepends() tea.Cmd {
+	i := m.todoIndex(m.dependsUUID)
+	if i < 0 {
+		return nil
+	}
+	t := m.todos[i]
+
+	var depends []string
+	for _, uuid := range t.depends {
+		// Keep links to tasks the picker did not list, such as deleted ones
+		if m.dependsChoices[uuid] || !m.isDependsOption(uuid) {
+			depends = append(depends, uuid)
+		}
+	}
+	for _, option := range m.dependsOptions {
+		if !m.dependsChoices[option.uuid] || containsString(depends, option.uuid) {
+			continue
+		}
+		if m.dependsOn(option.uuid, t.uuid, make(map[string]bool)) {
+			return m.notifyError(errors.New("cannot depend on " + option.text + ": it already depends on this task"))
+		}
+		depends = append(depends, option.uuid)
+	}
+
+	t.depends = depends
+	return m.updateTodo(t)
+}
+
+func (m *App) isDependsOption(uuid string) bool {
+	for _, option := range m.dependsOptions {
+		if option.uuid == uuid {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *App) renderDependsSelection() string {
+	var items []string
+	for i, option := range m.dependsOptions {
+		cursor := "  "
+		if i == m.dependsCursor {
+			cursor = "❯ "
+		}
+
+		selected := " "
+		if m.dependsChoices[option.uuid] {
+			selected = "✓"
+		}
+
+		line := fmt.Sprintf("%s[%s] %s", cursor, selected, option.text)
+		if i == m.dependsCursor {
+			line = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#1e1e2e")).
+				Background(lipgloss.Color("#f38ba8")).
+				Bold(true).
+				Render(line)
+		}
+
+		items = append(items, line)
+	}
+
+	content := strings.Join(items, "\n")
+	if len(items) == 0 {
+		content = "No other open tasks"
+	}
+
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#fab387")).
+		Bold(true).
+		Render("Depends On:")
+
+	instructions := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086")).
+		Render("Press space to toggle, enter to apply, esc to cancel")
+
+	style := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#6c7086")).
+		Padding(1, 2).
+		Width(50)
+
+	return style.Render(title + "\n\n" + content + "\n\n" + instructions)
+}
+
+// renderDependencyChain lists what t depends on as a tree, each dependency
+// followed by its own.
+func (m *App) renderDependencyChain(t todo) []string {
+	var lines []string
+	var walk func(depends []string, depth int, seen map[string]bool)
+	walk = func(depends []string, depth int, seen map[string]bool) {
+		for _, uuid := range depends {
+			i := m.todoIndex(uuid)
+			if i < 0 {
+				continue
+			}
+			dep := m.todos[i]
+
+			mark := "○"
+			if dep.completed {
+				mark = "✓"
+			}
+			line := strings.Repeat("  ", depth) + mark + " " + dep.text
+			if seen[uuid] {
+				lines = append(lines, line+" (cycle)")
+				continue
+			}
+
+			lines = append(lines, line)
+			seen[uuid] = true
+			walk(dep.depends, depth+1, seen)
+			delete(seen, uuid)
+		}
+	}
+	walk(t.depends, 0, map[string]bool{t.uuid: true})
+	return lines
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+// linkDepends makes t depend on each of on through the depends picker.
+func (h *appHarness) linkDepends(t todo, on ...todo) {
+	h.t.Helper()
+	h.app.selectTodo(t.uuid)
+	h.press("D")
+	for _, target := range on {
+		i := slices.IndexFunc(h.app.dependsOptions, func(option todo) bool { return option.uuid == target.uuid })
+		if i < 0 {
+			h.t.Fatalf("%s is not offered as a dependency", target.text)
+		}
+		h.app.dependsCursor = i
+		h.press(" ")
+	}
+	h.press("enter")
+	h.settle()
+}
+
+func TestDepends(t *testing.T) {
+	h := newHarness(t)
+	added := h.addTasks("design", "build", "ship")
+	design, build, ship := added[0], added[1], added[2]
+
+	h.linkDepends(build, design)
+	h.linkDepends(ship, build)
+	if task := h.stored(build.uuid); !slices.Equal(task.Depends, []string{design.uuid}) {
+		t.Errorf("build depends on %q, want design", task.Depends)
+	}
+
+	build = h.app.todos[h.app.todoIndex(build.uuid)]
+	if blockers := h.app.blockers(build); len(blockers) != 1 || blockers[0].uuid != design.uuid {
+		t.Errorf("build blocked by %v, want design", uuidsOf(blockers))
+	}
+	if blocking := h.app.blockingSet(); !blocking[design.uuid] || !blocking[build.uuid] || blocking[ship.uuid] {
+		t.Errorf("blocking set %v, want design and build", blocking)
+	}
+	if !h.app.dependsOn(ship.uuid, design.uuid, make(map[string]bool)) {
+		t.Error("ship does not depend on design through build")
+	}
+}
+
+func TestDependsRefusesCycle(t *testing.T) {
+	h := newHarness(t)
+	added := h.addTasks("design", "build")
+	design, build := added[0], added[1]
+
+	h.linkDepends(build, design)
+	h.linkDepends(design, build)
+	if task := h.stored(design.uuid); len(task.Depends) != 0 {
+		t.Errorf("design depends on %q, want the cycle refused", task.Depends)
+	}
+	if h.app.status == nil || h.app.status.severity != severityError {
+		t.Errorf("status %+v, want an error", h.app.status)
+	}
+}
+
+func TestCompleteBlockedAsks(t *testing.T) {
+	h := newHarness(t)
+	added := h.addTasks("design", "build")
+	design, build := added[0], added[1]
+	h.linkDepends(build, design)
+
+	h.app.selectTodo(build.uuid)
+	h.press(" ")
+	if h.app.confirmDialog == nil {
+		t.Fatal("completing a blocked task did not ask")
+	}
+	if items := h.app.confirmDialog.items; !slices.Equal(items, []string{"design"}) {
+		t.Errorf("dialog lists %q, want the blocker", items)
+	}
+	h.press("y")
+	h.settle()
+	if task := h.stored(build.uuid); task.Status != "completed" {
+		t.Errorf("status %q, want completed", task.Status)
+	}
+
+	// Once its blocker is done a task completes without asking
+	h.app.selectTodo(design.uuid)
+	h.press(" ")
+	h.settle()
+	if h.app.confirmDialog != nil {
+		t.Error("completing an unblocked task asked")
+	}
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/EwanGreer/todolist/taskwarrior"
+)
+
+func (m *App) startDetail() {
+	selected, ok := m.selectedTodo()
+	if !ok {
+		return
+	}
+
+	m.detailMode = true
+	m.detailUUID = selected.uuid
+	m.annotationCursor = 0
+	m.annotationInputMode = false
+	m.annotationText = ""
+}
+
+// detailTodo returns the todo shown in the detail view, which closes if the
+// task has gone away.
+func (m *App) detailTodo() (todo, bool) {
+	i := m.todoIndex(m.detailUUID)
+	if i < 0 {
+		return todo{}, false
+	}
+	return m.todos[i], true
+}
+
+func (m *App) handleDetailKey(msg tea.KeyMsg) tea.Cmd {
+	if m.annotationInputMode {
+		return m.handleAnnotationInputKey(msg)
+	}
+
+	t, ok := m.detailTodo()
+	if !ok {
+		m.detailMode = false
+		return nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.annotationCursor > 0 {
+			m.annotationCursor--
+		}
+	case "down", "j":
+		if m.annotationCursor < len(t.annotations)-1 {
+			m.annotationCursor++
+		}
+	case "a":
+		m.annotationInputMode = true
+		m.annotationText = ""
+	case "x":
+		return m.removeAnnotation(t, m.annotationCursor)
+	case "esc", "i", "q":
+		m.detailMode = false
+	case "ctrl+c":
+		return m.quit()
+	}
+	return nil
+}
+
+func (m *App) handleAnnotationInputKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		var cmd tea.Cmd
+		if text := strings.TrimSpace(m.annotationText); text != "" {
+			if t, ok := m.detailTodo(); ok {
+				cmd = m.addAnnotation(t, text)
+			}
+		}
+		m.annotationInputMode = false
+		m.annotationText = ""
+		return cmd
+	case "esc":
+		m.annotationInputMode = false
+		m.annotationText = ""
+		return nil
+	case "backspace":
+		if len(m.annotationText) > 0 {
+			m.annotationText = m.annotationText[:len(m.annotationText)-1]
+		}
+		return nil
+	case "ctrl+c":
+		return m.quit()
+	default:
+		if len(msg.String()) == 1 {
+			m.annotationText += msg.String()
+		}
+		return nil
+	}
+}
+
+func (m *App) addAnnotation(t todo, text string) tea.Cmd {
+	t = annotate(t, text)
+	m.annotationCursor = len(t.annotations) - 1
+	return m.updateTodo(t)
+}
+
+// annotate returns t with an annotation added now.
+func annotate(t todo, text string) todo {
+	annotation := taskwarrior.Annotation{Entry: time.Now().Unix(), Description: text}
+	// Taskwarrior keys annotations by their timestamp, so two added within
+	// the same second would collide
+	if n := len(t.annotations); n > 0 && annotation.Entry <= t.annotations[n-1].Entry {
+		annotation.Entry = t.annotations[n-1].Entry + 1
+	}
+	t.annotations = append(append([]taskwarrior.Annotation(nil), t.annotations...), annotation)
+	return t
+}
+
+func (m *App) removeAnnotation(t todo, index int) tea.Cmd {
+	if index < 0 || index >= len(t.annotations) {
+		return nil
+	}
+
+	return m.confirmItems("Remove this annotation?", []string{t.annotations[index].Description}, func() tea.Cmd {
+		annotations := append([]taskwarrior.Annotation(nil), t.annotations[:index]...)
+		t.annotations = append(annotations, t.annotations[index+1:]...)
+		if m.annotationCursor >= len(t.annotations) {
+			m.annotationCursor = max(len(t.annotations)-1, 0)
+		}
+		return m.updateTodo(t)
+	})
+}
+
+func formatTimestamp(ts int64) string {
+	if ts == 0 {
+		return ""
+	}
+	return time.Unix(ts, 0).Format("2006-01-02 15:04")
+}
+
+func (m *App) renderDetail() string {
+	t, ok := m.detailTodo()
+	if !ok {
+		return ""
+	}
+
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#fab387")).
+		Bold(true).
+		Render("Task Details")
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086")).
+		Width(10)
+
+	status := "pending"
+	switch {
+	case t.completed:
+		status = "completed"
+	case t.recurring:
+		status = "recurring"
+	}
+	due := ""
+	if t.due != 0 {
+		due = formatTimestamp(t.due) + " (" + formatDue(t.due, time.Now()) + ")"
+	}
+
+	started := ""
+	if t.start != 0 && !t.completed {
+		started = formatTimestamp(t.start) + " (" + formatElapsed(t.start, time.Now()) + ")"
+	}
+	until := ""
+	if t.until != 0 {
+		until = formatTimestamp(t.until)
+	}
+
+	var created, modified int64
+	if t.task != nil {
+		created, modified = t.task.Entry, t.task.Modified
+	}
+
+	fields := []struct{ label, value string }{
+		{"Task", t.text},
+		{"Status", status},
+		{"Project", t.project},
+		{"Priority", t.priority},
+		{"Due", due},
+		{"Recur", t.recur},
+		{"Until", until},
+		{"Started", started},
+		{"Tags", formatTags(t.tags)},
+		{"Created", formatTimestamp(created)},
+		{"Modified", formatTimestamp(modified)},
+		{"UUID", t.uuid},
+	}
+
+	var lines []string
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		lines = append(lines, labelStyle.Render(field.label)+field.value)
+	}
+
+	sectionStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#fab387")).
+		Bold(true)
+	heading := sectionStyle.Render(fmt.Sprintf("Annotations (%d)", len(t.annotations)))
+
+	var annotations []string
+	for i, annotation := range t.annotations {
+		cursor := "  "
+		if i == m.annotationCursor {
+			cursor = "❯ "
+		}
+
+		line := cursor + formatTimestamp(annotation.Entry) + "  " + annotation.Description
+		if i == m.annotationCursor && !m.annotationInputMode {
+			line = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#1e1e2e")).
+				Background(lipgloss.Color("#f38ba8")).
+				Bold(true).
+				Render(line)
+		}
+		annotations = append(annotations, line)
+	}
+	if len(annotations) == 0 {
+		annotations = append(annotations, labelStyle.UnsetWidth().Render("No annotations"))
+	}
+
+	content := title + "\n\n" + strings.Join(lines, "\n") + "\n\n" + heading + "\n" + strings.Join(annotations, "\n")
+
+	if chain := m.renderDependencyChain(t); len(chain) > 0 {
+		content += "\n\n" + sectionStyle.Render("Depends On") + "\n" + strings.Join(chain, "\n")
+	}
+	if dependents := m.dependents(t.uuid); len(dependents) > 0 {
+		var blocks []string
+		for _, dependent := range dependents {
+			blocks = append(blocks, "▸ "+dependent.text)
+		}
+		content += "\n\n" + sectionStyle.Render("Blocks") + "\n" + strings.Join(blocks, "\n")
+	}
+
+	hint := "a: annotate • x: remove annotation • ↑/↓: select • esc: close"
+	if m.annotationInputMode {
+		inputStyle := lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("#fab387")).
+			Padding(0, 1).
+			Width(60)
+
+		content += "\n\n" + inputStyle.Render(m.annotationText+"_")
+		hint = "enter to add the annotation • esc to cancel"
+	}
+
+	instructions := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086")).
+		Render(hint)
+
+	style := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#6c7086")).
+		Padding(1, 2).
+		Width(70)
+
+	return style.Render(content + "\n\n" + instructions)
+}
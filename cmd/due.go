@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// dueLayouts are the absolute date forms accepted after due:, tried in
+// order. Dates without a time are due at the start of the day, as in
+// Taskwarrior.
+var dueLayouts = []string{
+	"2006-01-02",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	"20060102T150405Z",
+}
+
+// parseDue turns the value of a due: attribute into a Unix time. It accepts
+// ISO dates, Taskwarrior synonyms such as tomorrow, eow and friday, and
+// durations from now such as +3d or 2w. An empty value clears the date.
+func parseDue(value string, now time.Time) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	if due, ok := dueSynonym(strings.ToLower(value), now); ok {
+		return due.Unix(), nil
+	}
+	if d, ok := parseDuration(strings.TrimPrefix(value, "+")); ok {
+		return now.Add(d).Unix(), nil
+	}
+	for _, layout := range dueLayouts {
+		loc := now.Location()
+		if strings.HasSuffix(layout, "Z") {
+			loc = time.UTC
+		}
+		if due, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return due.Unix(), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid due date %q: use a date like 2026-11-01, a name like tomorrow or eow, or a duration like +3d", value)
+}
+
+func dueSynonym(name string, now time.Time) (time.Time, bool) {
+	today := startOfDay(now)
+	endOfDay := func(t time.Time) time.Time { return t.AddDate(0, 0, 1).Add(-time.Second) }
+
+	switch name {
+	case "now":
+		return now, true
+	case "today", "sod":
+		return today, true
+	case "eod":
+		return endOfDay(today), true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case "eow":
+		// Weeks end on Sunday
+		return endOfDay(today.AddDate(0, 0, (7-int(today.Weekday()))%7)), true
+	case "sow":
+		return today.AddDate(0, 0, 7-(int(today.Weekday())+6)%7), true
+	case "eom":
+		return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()).Add(-time.Second), true
+	case "som":
+		return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), true
+	case "eoy":
+		return time.Date(today.Year()+1, 1, 1, 0, 0, 0, 0, today.Location()).Add(-time.Second), true
+	case "soy":
+		return time.Date(today.Year()+1, 1, 1, 0, 0, 0, 0, today.Location()), true
+	}
+
+	// A weekday means its next occurrence, a week ahead if it is today
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			days := (int(day) - int(today.Weekday()) + 6) % 7
+			return today.AddDate(0, 0, days+1), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseDuration reads Taskwarrior-style durations such as 3d, 12h, 2w,
+// 1mo and 1y. Months and years are approximated as 30 and 365 days.
+func parseDuration(value string) (time.Duration, bool) {
+	n, unit, ok := splitDuration(value)
+	if !ok {
+		return 0, false
+	}
+
+	day := 24 * time.Hour
+	switch unit {
+	case "h":
+		return time.Duration(n) * time.Hour, true
+	case "d":
+		return time.Duration(n) * day, true
+	case "w":
+		return time.Duration(n) * 7 * day, true
+	case "mo":
+		return time.Duration(n) * 30 * day, true
+	}
+	return time.Duration(n) * 365 * day, true
+}
+
+// splitDuration splits a duration into its count and one of the units h,
+// d, w, mo or y.
+func splitDuration(value string) (int, string, bool) {
+	i := 0
+	for i < len(value) && value[i] >= '0' && value[i] <= '9' {
+		i++
+	}
+	n, err := strconv.Atoi(value[:i])
+	if err != nil {
+		return 0, "", false
+	}
+
+	switch value[i:] {
+	case "h", "hr", "hrs", "hours":
+		return n, "h", true
+	case "d", "day", "days":
+		return n, "d", true
+	case "w", "wk", "wks", "weeks":
+		return n, "w", true
+	case "mo", "mos", "months":
+		return n, "mo", true
+	case "y", "yr", "yrs", "years":
+		return n, "y", true
+	}
+	return 0, "", false
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// formatDueInput is the inverse of parseDue, used to prefill the edit form.
+func formatDueInput(due int64) string {
+	t := time.Unix(due, 0)
+	if t.Equal(startOfDay(t)) {
+		return t.Format("2006-01-02")
+	}
+	// Keep the seconds of dates such as eod (23:59:59), or saving the form
+	// would move them
+	if t.Second() != 0 {
+		return t.Format("2006-01-02T15:04:05")
+	}
+	return t.Format("2006-01-02T15:04")
+}
+
+// formatDue renders a due date relative to now, in whole days: "today",
+// "in 2d" or "3d overdue", switching to weeks and years further out.
+func formatDue(due int64, now time.Time) string {
+	if due == 0 {
+		return ""
+	}
+
+	days := int(startOfDay(time.Unix(due, 0)).Sub(startOfDay(now)).Round(24*time.Hour) / (24 * time.Hour))
+	if days == 0 {
+		return "today"
+	}
+
+	span := days
+	if span < 0 {
+		span = -span
+	}
+	label := fmt.Sprintf("%dd", span)
+	switch {
+	case span >= 365:
+		label = fmt.Sprintf("%dy", span/365)
+	case span >= 14:
+		label = fmt.Sprintf("%dw", span/7)
+	}
+
+	if days < 0 {
+		return label + " overdue"
+	}
+	return "in " + label
+}
+
+type dueState int
+
+const (
+	dueNone dueState = iota
+	dueLater
+	dueToday
+	dueOverdue
+)
+
+func todoDueState(t todo, now time.Time) dueState {
+	// A template's due date is its first instance's
+	if t.due == 0 || t.completed || t.recurring {
+		return dueNone
+	}
+
+	due := time.Unix(t.due, 0)
+	switch {
+	case startOfDay(due).Equal(startOfDay(now)):
+		return dueToday
+	case due.Before(now):
+		return dueOverdue
+	}
+	return dueLater
+}
+
+// dueColor is the colour of a row that is due today or overdue.
+func dueColor(state dueState) lipgloss.Color {
+	switch state {
+	case dueToday:
+		return lipgloss.Color("#f9e2af")
+	case dueOverdue:
+		return lipgloss.Color("#f38ba8")
+	}
+	return lipgloss.Color("")
+}
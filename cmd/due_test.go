@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDueInputRoundTrip(t *testing.T) {
+	now := time.Date(2026, 10, 17, 9, 30, 0, 0, time.Local)
+
+	for _, input := range []string{"2026-10-20", "2026-10-20T17:00", "eod", "eom", "eoy", "tomorrow", "2026-10-20T08:15:42"} {
+		due, err := parseDue(input, now)
+		if err != nil {
+			t.Fatalf("parseDue(%q): %v", input, err)
+		}
+		prefill := formatDueInput(due)
+		again, err := parseDue(prefill, now)
+		if err != nil {
+			t.Fatalf("parseDue(%q), from %q: %v", prefill, input, err)
+		}
+		if again != due {
+			t.Errorf("%q prefilled as %q moves %s to %s", input, prefill, time.Unix(due, 0), time.Unix(again, 0))
+		}
+	}
+}
+
+func TestFormatDueInput(t *testing.T) {
+	for _, tc := range []struct {
+		due  time.Time
+		want string
+	}{
+		{time.Date(2026, 10, 20, 0, 0, 0, 0, time.Local), "2026-10-20"},
+		{time.Date(2026, 10, 20, 17, 0, 0, 0, time.Local), "2026-10-20T17:00"},
+		{time.Date(2026, 10, 20, 23, 59, 59, 0, time.Local), "2026-10-20T23:59:59"},
+	} {
+		if got := formatDueInput(tc.due.Unix()); got != tc.want {
+			t.Errorf("formatDueInput(%s) = %q, want %q", tc.due, got, tc.want)
+		}
+	}
+}
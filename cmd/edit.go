@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func (m *App) startEdit() {
+	selected, ok := m.selectedTodo()
+	if !ok || m.isPending(selected.uuid) {
+		return
+	}
+
+	m.editMode = true
+	m.editUUID = selected.uuid
+	m.editText = formatTaskWarriorInput(selected)
+}
+
+func (m *App) handleEditKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		var cmd tea.Cmd
+		if i := m.todoIndex(m.editUUID); i >= 0 && strings.TrimSpace(m.editText) != "" {
+			edited := m.todos[i]
+			input, err := parseTaskWarriorInput(m.editText, edited.tags)
+			if err != nil {
+				// Keep the form open so the input can be fixed
+				return m.notifyError(err)
+			}
+
+			if err := checkRecurEdit(edited, input); err != nil {
+				return m.notifyError(err)
+			}
+			input.apply(&edited)
+			cmd = m.updateTodo(edited)
+		}
+		m.editMode = false
+		m.editText = ""
+		m.editUUID = ""
+		return cmd
+	case "esc":
+		m.editMode = false
+		m.editText = ""
+		m.editUUID = ""
+		return nil
+	case "backspace":
+		if len(m.editText) > 0 {
+			m.editText = m.editText[:len(m.editText)-1]
+		}
+		return nil
+	case "ctrl+c":
+		return m.quit()
+	default:
+		if len(msg.String()) == 1 {
+			m.editText += msg.String()
+		}
+		return nil
+	}
+}
+
+func (m *App) renderEditForm() string {
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#fab387")).
+		Bold(true).
+		Render("Edit Task")
+
+	inputStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#fab387")).
+		Padding(0, 1).
+		Width(50)
+
+	inputField := inputStyle.Render(m.editText + "_")
+
+	instructions := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086")).
+		Render("Edit the description (use project:name to move it, priority:H/M/L, due:date, +tag/-tag) • enter to save • esc to cancel")
+
+	style := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#6c7086")).
+		Padding(1, 2).
+		Width(60)
+
+	return style.Render(title + "\n\n" + inputField + "\n\n" + instructions)
+}
@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/EwanGreer/todolist/timelog"
+)
+
+// Default pomodoro lengths, overridden with WithFocusDurations.
+const (
+	defaultFocusWork      = 25 * time.Minute
+	defaultFocusBreak     = 5 * time.Minute
+	defaultFocusLongBreak = 15 * time.Minute
+)
+
+// pomodorosPerLongBreak is how many pomodoros come before a long break.
+const pomodorosPerLongBreak = 4
+
+// bellOutput is where the terminal bell is rung. Bubble Tea owns stdout, so
+// the bell goes to the same terminal through stderr.
+var bellOutput io.Writer = os.Stderr
+
+type focusPhase int
+
+const (
+	focusWork focusPhase = iota
+	focusBreak
+	focusLongBreak
+)
+
+func (p focusPhase) String() string {
+	switch p {
+	case focusBreak:
+		return "Break"
+	case focusLongBreak:
+		return "Long break"
+	}
+	return "Focus"
+}
+
+// focusSession is a pomodoro cycle running for one task. While paused,
+// remaining holds the time left and ends is unused.
+type focusSession struct {
+	id        int
+	uuid      string
+	text      string
+	phase     focusPhase
+	ends      time.Time
+	length    time.Duration
+	paused    bool
+	remaining time.Duration
+	completed int
+}
+
+func (s *focusSession) left(now time.Time) time.Duration {
+	if s.paused {
+		return s.remaining
+	}
+	return max(s.ends.Sub(now), 0)
+}
+
+// focusTickMsg counts a session down. id ties it to the session that
+// started it, so ticks from a stopped session die out.
+type focusTickMsg struct {
+	id int
+}
+
+func focusTick(id int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return focusTickMsg{id: id}
+	})
+}
+
+func ringBell() tea.Msg {
+	fmt.Fprint(bellOutput, "\a")
+	return nil
+}
+
+// minFocusLength is the shortest phase allowed. Sessions count down in
+// one-second ticks, so a shorter phase would end on every tick.
+const minFocusLength = time.Second
+
+// checkFocusDurations reports the first of the pomodoro and break lengths
+// that is too short to count down, naming the flag that sets it.
+func checkFocusDurations(work, shortBreak, longBreak time.Duration) error {
+	for _, length := range []struct {
+		name string
+		d    time.Duration
+	}{
+		{"--focus-work", work},
+		{"--focus-break", shortBreak},
+		{"--focus-long-break", longBreak},
+	} {
+		if length.d < minFocusLength {
+			return fmt.Errorf("%s must be at least %s, got %s", length.name, minFocusLength, length.d)
+		}
+	}
+	return nil
+}
+
+// WithFocusDurations sets the length of a pomodoro and of the short and
+// long breaks between them. Lengths that fail checkFocusDurations leave the
+// defaults in place.
+func WithFocusDurations(work, shortBreak, longBreak time.Duration) AppOption {
+	return func(m *App) {
+		if checkFocusDurations(work, shortBreak, longBreak) != nil {
+			return
+		}
+		m.focusWork = work
+		m.focusBreak = shortBreak
+		m.focusLongBreak = longBreak
+	}
+}
+
+// startFocus opens the focus overlay, starting a session for the selected
+// task unless one is already running.
+func (m *App) startFocus() tea.Cmd {
+	if m.focus != nil {
+		m.focusMode = true
+		return nil
+	}
+
+	selected, ok := m.selectedTodo()
+	if !ok || selected.completed || selected.recurring {
+		return nil
+	}
+
+	m.nextFocusID++
+	m.focus = &focusSession{id: m.nextFocusID, uuid: selected.uuid, text: selected.text}
+	m.focusMode = true
+	m.beginPhase(focusWork, time.Now())
+	return focusTick(m.focus.id)
+}
+
+func (m *App) beginPhase(phase focusPhase, now time.Time) {
+	length := m.focusWork
+	switch phase {
+	case focusBreak:
+		length = m.focusBreak
+	case focusLongBreak:
+		length = m.focusLongBreak
+	}
+
+	m.focus.phase = phase
+	m.focus.length = length
+	m.focus.ends = now.Add(length)
+	m.focus.paused = false
+}
+
+func (m *App) handleFocusTick(msg focusTickMsg) tea.Cmd {
+	if m.focus == nil || msg.id != m.focus.id {
+		return nil
+	}
+
+	now := time.Now()
+	if m.focus.paused || m.focus.left(now) > 0 {
+		return focusTick(msg.id)
+	}
+	return tea.Batch(m.nextPhase(now), focusTick(msg.id))
+}
+
+// nextPhase moves the session on from the phase that just ended, recording
+// a finished pomodoro on the task.
+func (m *App) nextPhase(now time.Time) tea.Cmd {
+	s := m.focus
+	if s.phase != focusWork {
+		m.beginPhase(focusWork, now)
+		return tea.Batch(ringBell, m.notify(severityInfo, "Break over: back to "+s.text))
+	}
+
+	s.completed++
+	var cmd tea.Cmd
+	if i := m.todoIndex(s.uuid); i >= 0 {
+		cmd = m.updateTodo(annotate(m.todos[i], fmt.Sprintf("Pomodoro completed (%s)", timelog.FormatDuration(s.length))))
+	}
+
+	next := focusBreak
+	if s.completed%pomodorosPerLongBreak == 0 {
+		next = focusLongBreak
+	}
+	m.beginPhase(next, now)
+
+	message := fmt.Sprintf("Pomodoro %d done: take a %s", s.completed, strings.ToLower(next.String()))
+	return tea.Batch(cmd, ringBell, m.notify(severitySuccess, message))
+}
+
+func (m *App) handleFocusKey(msg tea.KeyMsg) tea.Cmd {
+	now := time.Now()
+	switch msg.String() {
+	case " ":
+		if m.focus.paused {
+			m.focus.ends = now.Add(m.focus.remaining)
+			m.focus.paused = false
+		} else {
+			m.focus.remaining = m.focus.left(now)
+			m.focus.paused = true
+		}
+	case "n":
+		// Skipping a pomodoro does not count it
+		if m.focus.phase == focusWork {
+			m.beginPhase(focusBreak, now)
+		} else {
+			m.beginPhase(focusWork, now)
+		}
+	case "x":
+		m.focus = nil
+		m.focusMode = false
+	case "esc", "p":
+		m.focusMode = false
+	case "ctrl+c", "q":
+		return m.quit()
+	}
+	return nil
+}
+
+// formatCountdown renders d as 24:59.
+func formatCountdown(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// focusInfo summarises a running session for the header.
+func (m *App) focusInfo() string {
+	if m.focus == nil {
+		return ""
+	}
+	info := fmt.Sprintf("%s %s: %s", m.focus.phase, formatCountdown(m.focus.left(time.Now())), m.focus.text)
+	if m.focus.paused {
+		info += " (paused)"
+	}
+	return info
+}
+
+func (m *App) renderFocus() string {
+	s := m.focus
+	left := s.left(time.Now())
+
+	phaseColor := lipgloss.Color("#f38ba8")
+	if s.phase != focusWork {
+		phaseColor = lipgloss.Color("#a6e3a1")
+	}
+
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#fab387")).
+		Bold(true).
+		Render(s.text)
+
+	phase := s.phase.String()
+	if s.paused {
+		phase += " (paused)"
+	}
+	countdown := lipgloss.NewStyle().
+		Foreground(phaseColor).
+		Bold(true).
+		Render(phase + "  " + formatCountdown(left))
+
+	const barWidth = 40
+	filled := barWidth
+	if s.length > 0 {
+		filled = int(float64(barWidth) * float64(s.length-left) / float64(s.length))
+	}
+	bar := lipgloss.NewStyle().Foreground(phaseColor).Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#45475a")).Render(strings.Repeat("░", barWidth-filled))
+
+	progress := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#cdd6f4")).
+		Render(fmt.Sprintf("%d completed • long break after every %d", s.completed, pomodorosPerLongBreak))
+
+	instructions := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086")).
+		Render("space: pause/resume • n: next phase • x: stop • esc: hide")
+
+	style := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(phaseColor).
+		Padding(1, 2).
+		Width(50)
+
+	return style.Render(title + "\n\n" + countdown + "\n" + bar + "\n\n" + progress + "\n\n" + instructions)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckFocusDurations(t *testing.T) {
+	if err := checkFocusDurations(defaultFocusWork, defaultFocusBreak, defaultFocusLongBreak); err != nil {
+		t.Errorf("defaults rejected: %v", err)
+	}
+	for _, lengths := range [][3]time.Duration{
+		{0, defaultFocusBreak, defaultFocusLongBreak},
+		{defaultFocusWork, -time.Minute, defaultFocusLongBreak},
+		{defaultFocusWork, defaultFocusBreak, time.Millisecond},
+	} {
+		if err := checkFocusDurations(lengths[0], lengths[1], lengths[2]); err == nil {
+			t.Errorf("lengths %v accepted", lengths)
+		}
+	}
+}
+
+func TestWithFocusDurationsKeepsDefaults(t *testing.T) {
+	h := newHarness(t, WithFocusDurations(0, 0, 0))
+	if h.app.focusWork != defaultFocusWork || h.app.focusBreak != defaultFocusBreak || h.app.focusLongBreak != defaultFocusLongBreak {
+		t.Errorf("lengths = %s, %s, %s, want the defaults", h.app.focusWork, h.app.focusBreak, h.app.focusLongBreak)
+	}
+
+	h = newHarness(t, WithFocusDurations(10*time.Minute, time.Minute, 2*time.Minute))
+	if h.app.focusWork != 10*time.Minute {
+		t.Errorf("focusWork = %s, want 10m", h.app.focusWork)
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/EwanGreer/todolist/taskwarrior"
+)
+
+// maxHistory bounds how many operations can be undone in one session.
+const maxHistory = 100
+
+type historyOrigin int
+
+const (
+	originUser historyOrigin = iota
+	originUndo
+	originRedo
+)
+
+// change is one task before and after a write. before is nil for an add and
+// after is nil for a delete.
+type change struct {
+	before *taskwarrior.Task
+	after  *taskwarrior.Task
+}
+
+// historyEntry holds the changes of one write batch, undone as a unit.
+type historyEntry []change
+
+// record pushes the changes of a successful write onto the stack its origin
+// calls for. A new user operation clears the redo stack. Writes sharing a
+// group are merged into one entry.
+func (m *App) record(changes []change, opts writeOpts) {
+	if len(changes) == 0 {
+		return
+	}
+
+	if opts.group != 0 && opts.group == m.lastGroup {
+		stack := &m.undoStack
+		if opts.origin == originUndo {
+			stack = &m.redoStack
+		}
+		if n := len(*stack); n > 0 {
+			(*stack)[n-1] = append((*stack)[n-1], changes...)
+			return
+		}
+	}
+	m.lastGroup = opts.group
+
+	entry := historyEntry(changes)
+	switch opts.origin {
+	case originUser:
+		m.undoStack = pushHistory(m.undoStack, entry)
+		m.redoStack = nil
+	case originUndo:
+		m.redoStack = pushHistory(m.redoStack, entry)
+	case originRedo:
+		m.undoStack = pushHistory(m.undoStack, entry)
+	}
+}
+
+func pushHistory(stack []historyEntry, entry historyEntry) []historyEntry {
+	stack = append(stack, entry)
+	if len(stack) > maxHistory {
+		stack = stack[len(stack)-maxHistory:]
+	}
+	return stack
+}
+
+// undo reverts the last entry on the undo stack. It waits for writes in
+// flight: their rows cannot be reverted yet and their entries are not on
+// the stack, so the entry would be dropped or applied out of order.
+func (m *App) undo() tea.Cmd {
+	if len(m.undoStack) == 0 {
+		return m.notify(severityInfo, "Nothing to undo")
+	}
+	if len(m.pending) > 0 {
+		return m.notify(severityWarning, "Still saving: undo once the changes are saved")
+	}
+
+	entry := m.undoStack[len(m.undoStack)-1]
+	m.undoStack = m.undoStack[:len(m.undoStack)-1]
+	return m.revertEntry(entry, writeOpts{verb: "undid", origin: originUndo, group: m.newGroup()})
+}
+
+func (m *App) redo() tea.Cmd {
+	if len(m.redoStack) == 0 {
+		return m.notify(severityInfo, "Nothing to redo")
+	}
+	if len(m.pending) > 0 {
+		return m.notify(severityWarning, "Still saving: redo once the changes are saved")
+	}
+
+	entry := m.redoStack[len(m.redoStack)-1]
+	m.redoStack = m.redoStack[:len(m.redoStack)-1]
+	return m.revertEntry(entry, writeOpts{verb: "redid", origin: originRedo, group: m.newGroup()})
+}
+
+func (m *App) newGroup() int {
+	m.nextGroup++
+	return m.nextGroup
+}
+
+// revertEntry writes every task in entry back to its before state. Undo and
+// redo share it: redoing is reverting the change the undo recorded.
+func (m *App) revertEntry(entry historyEntry, opts writeOpts) tea.Cmd {
+	var removed, revived, restored []todo
+	for _, c := range entry {
+		switch {
+		case c.before == nil:
+			removed = append(removed, todoFromTask(c.after.Clone()))
+		case c.after == nil:
+			revived = append(revived, todoFromTask(c.before.Clone()))
+		default:
+			restored = append(restored, todoFromTask(c.before.Clone()))
+		}
+	}
+
+	var cmds []tea.Cmd
+	if len(removed) > 0 {
+		cmds = append(cmds, m.deleteTodos(removed, opts))
+	}
+	if len(revived) > 0 {
+		cmds = append(cmds, m.addTodos(revived, opts))
+	}
+	if len(restored) > 0 {
+		cmds = append(cmds, m.updateTodos(restored, opts))
+	}
+	return tea.Batch(cmds...)
+}
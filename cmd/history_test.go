@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/EwanGreer/todolist/taskwarrior"
+)
+
+func changeOf(description string) []change {
+	return []change{{after: &taskwarrior.Task{Description: description}}}
+}
+
+func TestRecord(t *testing.T) {
+	h := newHarness(t)
+	m := h.app
+
+	m.record(changeOf("first"), writeOpts{})
+	m.record(changeOf("second"), writeOpts{})
+	if len(m.undoStack) != 2 || len(m.redoStack) != 0 {
+		t.Fatalf("undo %d, redo %d after two writes, want 2 and 0", len(m.undoStack), len(m.redoStack))
+	}
+
+	m.record(changeOf("undone"), writeOpts{origin: originUndo, group: 1})
+	if len(m.redoStack) != 1 {
+		t.Fatalf("redo holds %d entries after an undo, want 1", len(m.redoStack))
+	}
+
+	// The other half of the same undo joins its entry
+	m.record(changeOf("undone too"), writeOpts{origin: originUndo, group: 1})
+	if len(m.redoStack) != 1 || len(m.redoStack[0]) != 2 {
+		t.Errorf("redo holds %d entries after a grouped write, want 1 of 2 changes", len(m.redoStack))
+	}
+
+	m.record(changeOf("redone"), writeOpts{origin: originRedo, group: 2})
+	if len(m.undoStack) != 3 || len(m.redoStack) != 1 {
+		t.Errorf("undo %d, redo %d after a redo, want 3 and 1", len(m.undoStack), len(m.redoStack))
+	}
+
+	m.record(changeOf("new"), writeOpts{})
+	if len(m.redoStack) != 0 {
+		t.Errorf("redo holds %d entries after a new write, want 0", len(m.redoStack))
+	}
+
+	m.record(nil, writeOpts{})
+	if len(m.undoStack) != 4 {
+		t.Errorf("undo holds %d entries after an empty write, want 4", len(m.undoStack))
+	}
+}
+
+func TestRecordKeepsMaxHistory(t *testing.T) {
+	h := newHarness(t)
+	for range maxHistory + 5 {
+		h.app.record(changeOf("write"), writeOpts{})
+	}
+	if len(h.app.undoStack) != maxHistory {
+		t.Errorf("undo holds %d entries, want %d", len(h.app.undoStack), maxHistory)
+	}
+}
+
+func TestUndoNothing(t *testing.T) {
+	h := newHarness(t)
+	h.press("u")
+	if h.app.status == nil || h.app.status.text != "Nothing to undo" {
+		t.Errorf("status %+v, want nothing to undo", h.app.status)
+	}
+	h.press("ctrl+r")
+	if h.app.status == nil || h.app.status.text != "Nothing to redo" {
+		t.Errorf("status %+v, want nothing to redo", h.app.status)
+	}
+}
+
+func TestUndoRedoEdit(t *testing.T) {
+	h := newHarness(t)
+	added := h.addTask("Draft plan project:work")
+
+	edited := added
+	edited.text = "Final plan"
+	edited.project = "home"
+	h.run(h.app.updateTodo(edited))
+	h.settle()
+
+	h.press("u")
+	h.settle()
+	if task := h.stored(added.uuid); task.Description != "Draft plan" || task.Project != "work" {
+		t.Errorf("undoing the edit left %q in %q", task.Description, task.Project)
+	}
+
+	h.press("ctrl+r")
+	h.settle()
+	if task := h.stored(added.uuid); task.Description != "Final plan" || task.Project != "home" {
+		t.Errorf("redoing the edit left %q in %q", task.Description, task.Project)
+	}
+
+	// Undo all the way back to before the add
+	h.press("u")
+	h.settle()
+	h.press("u")
+	h.settle()
+	if task := h.stored(added.uuid); task.Status != "deleted" {
+		t.Errorf("undoing the add left status %q", task.Status)
+	}
+	if len(h.app.undoStack) != 0 || len(h.app.redoStack) != 2 {
+		t.Errorf("undo %d, redo %d, want 0 and 2", len(h.app.undoStack), len(h.app.redoStack))
+	}
+}
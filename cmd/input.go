@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// taskInput is a task as typed into the add and edit forms, in the
+// Taskwarrior command-line style: "Write report project:work priority:H
+// due:friday +review".
+type taskInput struct {
+	description string
+	project     string
+	priority    string
+	due         int64
+	recur       string
+	until       int64
+	tags        []string
+	removedTags []string
+}
+
+// parseTaskWarriorInput parses the add or edit form. tags are the tags the
+// task already has: -tag removes one of them, and any other -word, such as
+// the -v in "Document the -v flag", is part of the description.
+func parseTaskWarriorInput(input string, tags []string) (taskInput, error) {
+	parsed := taskInput{project: "default"}
+
+	var words []string
+	for _, word := range strings.Fields(input) {
+		if tag, add, ok := parseTagWord(word); ok {
+			switch {
+			case add:
+				parsed.tags = append(parsed.tags, tag)
+				continue
+			case containsString(tags, tag):
+				parsed.removedTags = append(parsed.removedTags, tag)
+				continue
+			}
+		}
+
+		name, value, ok := strings.Cut(word, ":")
+		if !ok {
+			words = append(words, word)
+			continue
+		}
+
+		switch strings.ToLower(name) {
+		case "project":
+			if value != "" {
+				parsed.project = value
+			}
+		case "priority", "pri":
+			priority, err := parsePriority(value)
+			if err != nil {
+				return taskInput{}, err
+			}
+			parsed.priority = priority
+		case "due":
+			due, err := parseDue(value, time.Now())
+			if err != nil {
+				return taskInput{}, err
+			}
+			parsed.due = due
+		case "recur":
+			recur, err := parseRecur(value)
+			if err != nil {
+				return taskInput{}, err
+			}
+			parsed.recur = recur
+		case "until":
+			until, err := parseDue(value, time.Now())
+			if err != nil {
+				return taskInput{}, err
+			}
+			parsed.until = until
+		default:
+			words = append(words, word)
+		}
+	}
+
+	parsed.description = strings.Join(words, " ")
+	if parsed.recur != "" && parsed.due == 0 {
+		return taskInput{}, errors.New("a recurring task needs a due date: add due:")
+	}
+	return parsed, nil
+}
+
+// apply copies the parsed attributes onto t. The input describes the whole
+// task, so an attribute left out of it is cleared.
+func (in taskInput) apply(t *todo) {
+	t.text = in.description
+	t.project = in.project
+	t.priority = in.priority
+	t.due = in.due
+	t.recur = in.recur
+	t.until = in.until
+
+	t.tags = nil
+	for _, tag := range in.tags {
+		if !containsString(in.removedTags, tag) && !containsString(t.tags, tag) {
+			t.tags = append(t.tags, tag)
+		}
+	}
+}
+
+// formatTaskWarriorInput is the inverse of parseTaskWarriorInput, used to
+// prefill the edit form.
+func formatTaskWarriorInput(t todo) string {
+	input := t.text
+	if t.project != "" && t.project != "default" {
+		input += " project:" + t.project
+	}
+	if t.priority != "" {
+		input += " priority:" + t.priority
+	}
+	if t.due != 0 {
+		input += " due:" + formatDueInput(t.due)
+	}
+	if t.recur != "" {
+		input += " recur:" + t.recur
+	}
+	if t.until != 0 {
+		input += " until:" + formatDueInput(t.until)
+	}
+	if len(t.tags) > 0 {
+		input += " " + formatTags(t.tags)
+	}
+	return input
+}
+
+func parsePriority(value string) (string, error) {
+	priority := strings.ToUpper(value)
+	switch priority {
+	case "", "H", "M", "L":
+		return priority, nil
+	}
+	return "", fmt.Errorf("invalid priority %q: use H, M or L", value)
+}
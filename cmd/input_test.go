@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseTaskWarriorInputKeepsDashWords(t *testing.T) {
+	for _, input := range []string{
+		"Document the -v flag for grep",
+		"Fix -review handling",
+		"Try -5 degrees",
+	} {
+		parsed, err := parseTaskWarriorInput(input, nil)
+		if err != nil {
+			t.Fatalf("parseTaskWarriorInput(%q): %v", input, err)
+		}
+		if parsed.description != input {
+			t.Errorf("parseTaskWarriorInput(%q) description = %q", input, parsed.description)
+		}
+		if len(parsed.removedTags) != 0 {
+			t.Errorf("parseTaskWarriorInput(%q) removed tags %v", input, parsed.removedTags)
+		}
+	}
+}
+
+func TestParseTaskWarriorInputRemovesExistingTags(t *testing.T) {
+	existing := todo{text: "Review PR", tags: []string{"review", "urgent"}}
+
+	input := formatTaskWarriorInput(existing) + " -urgent -v"
+	parsed, err := parseTaskWarriorInput(input, existing.tags)
+	if err != nil {
+		t.Fatalf("parseTaskWarriorInput(%q): %v", input, err)
+	}
+
+	edited := existing
+	parsed.apply(&edited)
+	if edited.text != "Review PR -v" {
+		t.Errorf("description = %q, want the -v kept", edited.text)
+	}
+	if !slices.Equal(edited.tags, []string{"review"}) {
+		t.Errorf("tags = %v, want [review]", edited.tags)
+	}
+}
+
+func TestParseTaskWarriorInputAttributes(t *testing.T) {
+	parsed, err := parseTaskWarriorInput("Write report project:work.api pri:h +docs", nil)
+	if err != nil {
+		t.Fatalf("parseTaskWarriorInput: %v", err)
+	}
+	if parsed.description != "Write report" || parsed.project != "work.api" || parsed.priority != "H" {
+		t.Errorf("parsed %+v", parsed)
+	}
+	if !slices.Equal(parsed.tags, []string{"docs"}) {
+		t.Errorf("tags = %v, want [docs]", parsed.tags)
+	}
+
+	if _, err := parseTaskWarriorInput("Pay rent recur:monthly", nil); err == nil {
+		t.Error("a recurring task without a due date was accepted")
+	}
+	if _, err := parseTaskWarriorInput("Pay rent priority:X", nil); err == nil {
+		t.Error("an invalid priority was accepted")
+	}
+}
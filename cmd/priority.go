@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// priorities lists the Taskwarrior priorities from lowest to highest, with
+// no priority first.
+var priorities = []string{"", "L", "M", "H"}
+
+func priorityRank(priority string) int {
+	for i, p := range priorities {
+		if p == priority {
+			return i
+		}
+	}
+	return 0
+}
+
+// shiftPriority raises the priority of the selected task by delta steps,
+// lowering it for a negative delta.
+func (m *App) shiftPriority(delta int) tea.Cmd {
+	selected, ok := m.selectedTodo()
+	if !ok {
+		return nil
+	}
+
+	rank := min(max(priorityRank(selected.priority)+delta, 0), len(priorities)-1)
+	if priorities[rank] == selected.priority {
+		return nil
+	}
+	selected.priority = priorities[rank]
+	return m.updateTodo(selected)
+}
+
+func priorityColor(priority string) lipgloss.Color {
+	switch priority {
+	case "H":
+		return lipgloss.Color("#f38ba8")
+	case "M":
+		return lipgloss.Color("#f9e2af")
+	case "L":
+		return lipgloss.Color("#89b4fa")
+	}
+	return lipgloss.Color("")
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// getUniqueProjects lists every project in todos along with the projects
+// they are nested in, in tree order: "work" comes before "work.api", which
+// comes before "work2".
+func getUniqueProjects(todos []todo) []string {
+	projectMap := make(map[string]bool)
+	for _, todo := range todos {
+		for _, project := range projectAncestors(todo.project) {
+			projectMap[project] = true
+		}
+	}
+
+	var projects []string
+	for project := range projectMap {
+		projects = append(projects, project)
+	}
+	slices.SortFunc(projects, func(a, b string) int {
+		return slices.Compare(strings.Split(a, "."), strings.Split(b, "."))
+	})
+	return projects
+}
+
+// projectAncestors returns project and each project it is nested in, as
+// "work.backend.api", "work.backend" and "work".
+func projectAncestors(project string) []string {
+	ancestors := []string{project}
+	for i := strings.LastIndex(project, "."); i > 0; i = strings.LastIndex(project, ".") {
+		project = project[:i]
+		ancestors = append(ancestors, project)
+	}
+	return ancestors
+}
+
+// inProject reports whether project is filter or nested under it, as
+// Taskwarrior's project:filter does.
+func inProject(project, filter string) bool {
+	return filter == "all" || project == filter || strings.HasPrefix(project, filter+".")
+}
+
+func (m *App) hasSubprojects(project string) bool {
+	for _, p := range m.projects {
+		if strings.HasPrefix(p, project+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// visibleProjects lists the projects in the filter menu that are not
+// inside a collapsed project.
+func (m *App) visibleProjects() []string {
+	var visible []string
+	for _, project := range m.projects {
+		hidden := false
+		for _, ancestor := range projectAncestors(project)[1:] {
+			if m.collapsedProjects[ancestor] {
+				hidden = true
+				break
+			}
+		}
+		if !hidden {
+			visible = append(visible, project)
+		}
+	}
+	return visible
+}
+
+// projectCounts counts the open tasks in each project, including those in
+// its subprojects.
+func (m *App) projectCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, t := range m.todos {
+		if t.completed || t.recurring {
+			continue
+		}
+		counts["all"]++
+		for _, project := range projectAncestors(t.project) {
+			counts[project]++
+		}
+	}
+	return counts
+}
+
+func (m *App) startProjectSelection() {
+	// Open the projects around the current filter so the cursor can land on it
+	for _, ancestor := range projectAncestors(m.currentFilter)[1:] {
+		delete(m.collapsedProjects, ancestor)
+	}
+
+	m.projectCursor = 0
+	for i, project := range m.visibleProjects() {
+		if project == m.currentFilter {
+			m.projectCursor = i
+			break
+		}
+	}
+	m.projectSelectionMode = true
+}
+
+func (m *App) handleProjectSelectionKey(msg tea.KeyMsg) tea.Cmd {
+	visible := m.visibleProjects()
+	if m.projectCursor >= len(visible) {
+		m.projectCursor = max(len(visible)-1, 0)
+	}
+	project := ""
+	if len(visible) > 0 {
+		project = visible[m.projectCursor]
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.projectCursor > 0 {
+			m.projectCursor--
+		}
+	case "down", "j":
+		if m.projectCursor < len(visible)-1 {
+			m.projectCursor++
+		}
+	case "left", "h":
+		if m.hasSubprojects(project) && !m.collapsedProjects[project] {
+			m.collapsedProjects[project] = true
+			break
+		}
+		// Otherwise step out to the parent project
+		if ancestors := projectAncestors(project); len(ancestors) > 1 {
+			m.projectCursor = max(slices.Index(visible, ancestors[1]), 0)
+		}
+	case "right", "l":
+		delete(m.collapsedProjects, project)
+	case "enter", " ":
+		m.currentFilter = project
+		m.projectSelectionMode = false
+		m.updateTable()
+	case "c":
+		return m.completeProject(project)
+	case "d":
+		return m.deleteProject(project)
+	case "esc":
+		m.projectSelectionMode = false
+	case "ctrl+c", "q":
+		return m.quit()
+	}
+	return nil
+}
+
+// projectTodos returns the open tasks in project and its subprojects, in
+// display order.
+func (m *App) projectTodos(project string) []todo {
+	var todos []todo
+	for _, t := range m.todos {
+		if !t.completed && inProject(t.project, project) {
+			todos = append(todos, t)
+		}
+	}
+	sortTodos(todos, m.sortMode)
+	return groupRecurring(todos)
+}
+
+// completeProject completes every open task in project, after asking.
+func (m *App) completeProject(project string) tea.Cmd {
+	if project == "all" {
+		return nil
+	}
+	todos := completable(m.projectTodos(project))
+	if len(todos) == 0 {
+		return m.notify(severityInfo, fmt.Sprintf("No open tasks in %s", project))
+	}
+
+	m.projectSelectionMode = false
+	return m.confirm(fmt.Sprintf("Complete %s in %s?", pluralTasks(len(todos)), project), todos, func() tea.Cmd {
+		return m.completeTodos(todos, "completed")
+	})
+}
+
+// deleteProject deletes every open task in project, recurring templates
+// included, after asking.
+func (m *App) deleteProject(project string) tea.Cmd {
+	if project == "all" {
+		return nil
+	}
+	todos := m.projectTodos(project)
+	if len(todos) == 0 {
+		return m.notify(severityInfo, fmt.Sprintf("No open tasks in %s", project))
+	}
+
+	m.projectSelectionMode = false
+	return m.confirm(fmt.Sprintf("Delete %s in %s?", pluralTasks(len(todos)), project), todos, func() tea.Cmd {
+		return m.deleteTodos(todos, writeOpts{verb: "deleted"})
+	})
+}
+
+func (m *App) renderProjectSelection() string {
+	counts := m.projectCounts()
+
+	var items []string
+	for i, project := range m.visibleProjects() {
+		cursor := "  "
+		if i == m.projectCursor {
+			cursor = "❯ "
+		}
+
+		selected := " "
+		if project == m.currentFilter {
+			selected = "✓"
+		}
+
+		displayName := project
+		if project == "all" {
+			displayName = "all projects"
+		} else {
+			depth := strings.Count(project, ".")
+			branch := "  "
+			if m.hasSubprojects(project) {
+				branch = "▾ "
+				if m.collapsedProjects[project] {
+					branch = "▸ "
+				}
+			}
+			displayName = strings.Repeat("  ", depth) + branch + project[strings.LastIndex(project, ".")+1:]
+		}
+
+		line := fmt.Sprintf("%s[%s] %s (%d)", cursor, selected, displayName, counts[project])
+		if i == m.projectCursor {
+			line = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#1e1e2e")).
+				Background(lipgloss.Color("#f38ba8")).
+				Bold(true).
+				Render(line)
+		}
+
+		items = append(items, line)
+	}
+
+	content := strings.Join(items, "\n")
+
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#fab387")).
+		Bold(true).
+		Render("Select Project Filter:")
+
+	instructions := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086")).
+		Render("Press enter to select, ←/→ to collapse or expand, c/d to complete or delete its tasks, esc to cancel")
+
+	style := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#6c7086")).
+		Padding(1, 2).
+		Width(45)
+
+	return style.Render(title + "\n\n" + content + "\n\n" + instructions)
+}
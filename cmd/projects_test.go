@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+// openProject opens the project picker with the cursor on project.
+func (h *appHarness) openProject(project string) {
+	h.t.Helper()
+	h.app.startProjectSelection()
+	for i, p := range h.app.visibleProjects() {
+		if p == project {
+			h.app.projectCursor = i
+			return
+		}
+	}
+	h.t.Fatalf("project %s is not in the picker", project)
+}
+
+func TestProjectActions(t *testing.T) {
+	h := newHarness(t)
+	added := h.addTasks("api docs project:work.api", "review project:work", "groceries project:home")
+	work := added[:2]
+
+	h.openProject("work")
+	h.press("c")
+	if h.app.confirmDialog == nil || h.app.confirmDialog.prompt != "Complete 2 tasks in work?" {
+		t.Fatalf("dialog %+v, want one completing 2 tasks in work", h.app.confirmDialog)
+	}
+	h.press("y")
+	h.settle()
+	for _, row := range work {
+		if task := h.stored(row.uuid); task.Status != "completed" {
+			t.Errorf("%s status %q, want completed", row.text, task.Status)
+		}
+	}
+	if task := h.stored(added[2].uuid); task.Status != "pending" {
+		t.Errorf("task in another project status %q, want pending", task.Status)
+	}
+
+	h.openProject("home")
+	h.press("d")
+	if h.app.confirmDialog == nil || h.app.confirmDialog.prompt != "Delete 1 task in home?" {
+		t.Fatalf("dialog %+v, want one deleting 1 task in home", h.app.confirmDialog)
+	}
+	h.press("esc")
+	if task := h.stored(added[2].uuid); task.Status != "pending" {
+		t.Errorf("cancelled delete left status %q", task.Status)
+	}
+
+	// Nothing is left open in work
+	h.openProject("work")
+	h.press("d")
+	if h.app.confirmDialog != nil {
+		t.Error("asked to delete a project with no open tasks")
+	}
+}
+
+func TestGetUniqueProjects(t *testing.T) {
+	todos := []todo{{project: "work2"}, {project: "work.api.v2"}, {project: "home"}, {project: "work.api"}, {project: "work"}}
+	want := []string{"home", "work", "work.api", "work.api.v2", "work2"}
+	if got := getUniqueProjects(todos); !slices.Equal(got, want) {
+		t.Errorf("getUniqueProjects = %q, want %q", got, want)
+	}
+
+	// Parents without tasks of their own are listed too
+	want = []string{"a", "a.b", "a.b.c"}
+	if got := getUniqueProjects([]todo{{project: "a.b.c"}}); !slices.Equal(got, want) {
+		t.Errorf("getUniqueProjects = %q, want %q", got, want)
+	}
+}
+
+func TestInProject(t *testing.T) {
+	for _, tc := range []struct {
+		project, filter string
+		want            bool
+	}{
+		{"work", "all", true},
+		{"work", "work", true},
+		{"work.api", "work", true},
+		{"work.api.v2", "work", true},
+		{"work2", "work", false},
+		{"work", "work.api", false},
+		{"home", "work", false},
+	} {
+		if got := inProject(tc.project, tc.filter); got != tc.want {
+			t.Errorf("inProject(%q, %q) = %v, want %v", tc.project, tc.filter, got, tc.want)
+		}
+	}
+}
+
+func TestProjectTree(t *testing.T) {
+	h := newHarness(t)
+	h.addTasks("a project:work.api", "b project:work.api", "c project:work.web", "d project:work", "e project:home")
+	done := h.addTask("f project:work.web")
+	h.app.selectTodo(done.uuid)
+	h.press(" ")
+	h.settle()
+
+	counts := h.app.projectCounts()
+	for project, want := range map[string]int{"all": 5, "work": 4, "work.api": 2, "work.web": 1, "home": 1} {
+		if counts[project] != want {
+			t.Errorf("%s counts %d open tasks, want %d", project, counts[project], want)
+		}
+	}
+
+	h.openProject("work")
+	h.press("h")
+	want := []string{"all", "home", "work"}
+	if got := h.app.visibleProjects(); !slices.Equal(got, want) {
+		t.Errorf("collapsed tree shows %q, want %q", got, want)
+	}
+
+	h.press("l", "j", "j")
+	if got := h.app.visibleProjects()[h.app.projectCursor]; got != "work.web" {
+		t.Fatalf("cursor on %s, want work.web", got)
+	}
+	// h on a leaf steps out to its parent
+	h.press("h")
+	if got := h.app.visibleProjects()[h.app.projectCursor]; got != "work" {
+		t.Errorf("h on a leaf moved to %s, want work", got)
+	}
+
+	h.press("enter")
+	var texts []string
+	for _, row := range h.app.getFilteredTodos() {
+		texts = append(texts, row.text)
+	}
+	slices.Sort(texts)
+	if want := []string{"a", "b", "c", "d", "f"}; !slices.Equal(texts, want) {
+		t.Errorf("filtering by work shows %q, want %q", texts, want)
+	}
+}
+
+func TestProjectSelectionOpensAroundFilter(t *testing.T) {
+	h := newHarness(t)
+	h.addTask("a project:work.api")
+	h.app.currentFilter = "work.api"
+	h.app.collapsedProjects["work"] = true
+
+	h.app.startProjectSelection()
+	if got := h.app.visibleProjects()[h.app.projectCursor]; got != "work.api" {
+		t.Errorf("picker opened on %s, want work.api", got)
+	}
+}
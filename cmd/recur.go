@@ -0,0 +1,414 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/EwanGreer/todolist/taskwarrior"
+)
+
+// Taskwarrior keeps a recurring task as a template with status "recurring"
+// and generates a pending instance for each period. The template's mask
+// holds one character per instance generated so far ('-' pending, '+'
+// completed, 'X' deleted) and each instance's imask is its index in it.
+
+// recurPeriods are the named recurrence periods accepted after recur:.
+var recurPeriods = []string{
+	"daily", "weekdays", "weekly", "biweekly", "monthly", "quarterly", "semiannual", "yearly", "annual",
+}
+
+// parseRecur validates the value of a recur: attribute: a named period such
+// as weekly, or a duration such as 2w or 3mo.
+func parseRecur(value string) (string, error) {
+	recur := strings.ToLower(value)
+	if recur == "" || containsString(recurPeriods, recur) {
+		return recur, nil
+	}
+	if _, _, ok := splitDuration(recur); ok {
+		return recur, nil
+	}
+	return "", fmt.Errorf("invalid recurrence %q: use a period like %s, or a duration like 2w", value, strings.Join(recurPeriods[:5], ", "))
+}
+
+// recurrenceDue is the due date of the instance at index for a template
+// first due at base. Months and years follow the calendar rather than a
+// fixed number of days.
+func recurrenceDue(base int64, recur string, index int) (int64, bool) {
+	due := time.Unix(base, 0)
+	switch recur {
+	case "daily":
+		return due.AddDate(0, 0, index).Unix(), true
+	case "weekdays":
+		for range index {
+			due = due.AddDate(0, 0, 1)
+			for due.Weekday() == time.Saturday || due.Weekday() == time.Sunday {
+				due = due.AddDate(0, 0, 1)
+			}
+		}
+		return due.Unix(), true
+	case "weekly":
+		return due.AddDate(0, 0, 7*index).Unix(), true
+	case "biweekly":
+		return due.AddDate(0, 0, 14*index).Unix(), true
+	case "monthly":
+		return due.AddDate(0, index, 0).Unix(), true
+	case "quarterly":
+		return due.AddDate(0, 3*index, 0).Unix(), true
+	case "semiannual":
+		return due.AddDate(0, 6*index, 0).Unix(), true
+	case "yearly", "annual":
+		return due.AddDate(index, 0, 0).Unix(), true
+	}
+
+	n, unit, ok := splitDuration(recur)
+	if !ok || n == 0 {
+		return 0, false
+	}
+	switch unit {
+	case "mo":
+		return due.AddDate(0, n*index, 0).Unix(), true
+	case "y":
+		return due.AddDate(n*index, 0, 0).Unix(), true
+	}
+	d, _ := parseDuration(recur)
+	return due.Add(d * time.Duration(index)).Unix(), true
+}
+
+// checkRecurEdit refuses edits that would turn a plain task into a
+// recurring one or drop a recurrence, which need their own actions.
+func checkRecurEdit(t todo, in taskInput) error {
+	switch {
+	case t.recur == "" && in.recur != "":
+		return errors.New("recur: can only be set when adding a task")
+	case t.recur != "" && in.recur == "":
+		return errors.New("recur: cannot be removed here: press R to stop the recurrence")
+	}
+	return nil
+}
+
+func recurrenceMask(t todo) string {
+	if t.task == nil {
+		return ""
+	}
+	mask, _ := t.task.UDA["mask"].(string)
+	return mask
+}
+
+// instanceIndex returns the instance's position in its template's mask.
+// imask is a string in the data files and a number in exports.
+func instanceIndex(t todo) (int, bool) {
+	if t.task == nil {
+		return 0, false
+	}
+	switch imask := t.task.UDA["imask"].(type) {
+	case float64:
+		return int(imask), true
+	case int:
+		return imask, true
+	case string:
+		index, err := strconv.Atoi(imask)
+		return index, err == nil
+	}
+	return 0, false
+}
+
+// withUDA returns t with the UDA name set on a copy of its task.
+func withUDA(t todo, name string, value any) todo {
+	task := &taskwarrior.Task{}
+	if t.task != nil {
+		task = t.task.Clone()
+	}
+	if task.UDA == nil {
+		task.UDA = make(map[string]any)
+	}
+	task.UDA[name] = value
+	t.task = task
+	return t
+}
+
+// addRecurring adds a template for t along with its first instance.
+func (m *App) addRecurring(t todo) tea.Cmd {
+	uuid, err := taskwarrior.NewUUID()
+	if err != nil {
+		return m.notifyError(fmt.Errorf("could not save task: %w", err))
+	}
+
+	template := t
+	template.uuid = uuid
+	template.recurring = true
+	template = withUDA(template, "mask", "-")
+
+	instance, err := newInstance(template, 0, t.due)
+	if err != nil {
+		return m.notifyError(fmt.Errorf("could not save task: %w", err))
+	}
+	return m.addTodos([]todo{template, instance}, writeOpts{})
+}
+
+// newInstance generates the pending instance at index from template.
+func newInstance(template todo, index int, due int64) (todo, error) {
+	uuid, err := taskwarrior.NewUUID()
+	if err != nil {
+		return todo{}, err
+	}
+
+	instance := template
+	instance.uuid = uuid
+	instance.recurring = false
+	instance.parent = template.uuid
+	instance.due = due
+	instance.createdAt = time.Now().Unix()
+	instance.task = &taskwarrior.Task{UDA: map[string]any{"imask": index}}
+	return instance, nil
+}
+
+// recurringTemplate returns the template t was generated from, or t itself
+// if it is one.
+func (m *App) recurringTemplate(t todo) (todo, bool) {
+	if t.recurring {
+		return t, true
+	}
+	if i := m.todoIndex(t.parent); i >= 0 && m.todos[i].recurring {
+		return m.todos[i], true
+	}
+	return todo{}, false
+}
+
+// completeInstance completes a pending instance, marks it done in the
+// template's mask and, when no other instance is pending, generates the
+// next one. It is saved as one batch so undo reverts all of it.
+func (m *App) completeInstance(instance todo) tea.Cmd {
+	instance.completed = true
+	batch, err := m.completionBatch([]todo{instance})
+	if err != nil {
+		return m.notifyError(fmt.Errorf("could not save task: %w", err))
+	}
+
+	return m.confirmComplete([]todo{instance}, func() tea.Cmd {
+		return m.saveTodos(batch, writeOpts{})
+	})
+}
+
+// completionBatch returns the writes that complete instances: the instances
+// themselves, the next instance of each recurrence left with none pending,
+// and each template with the completed instances marked in its mask.
+func (m *App) completionBatch(instances []todo) ([]todo, error) {
+	var batch []todo
+	completing := make(map[string]bool)
+	templates := make(map[string]todo)
+	var order []string
+	for _, instance := range instances {
+		instance.completed = true
+		completing[instance.uuid] = true
+		batch = append(batch, instance)
+
+		template, ok := templates[instance.parent]
+		if !ok {
+			if template, ok = m.recurringTemplate(instance); !ok {
+				continue
+			}
+			order = append(order, template.uuid)
+		}
+		mask := []byte(recurrenceMask(template))
+		if index, ok := instanceIndex(instance); ok && index < len(mask) {
+			mask[index] = '+'
+		}
+		templates[template.uuid] = withUDA(template, "mask", string(mask))
+	}
+
+	for _, uuid := range order {
+		template := templates[uuid]
+		if !m.hasPendingInstance(uuid, completing) {
+			mask := recurrenceMask(template)
+			index := len(mask)
+			due, ok := recurrenceDue(template.due, template.recur, index)
+			if ok && (template.until == 0 || due <= template.until) {
+				next, err := newInstance(template, index, due)
+				if err != nil {
+					return nil, err
+				}
+				batch = append(batch, next)
+				template = withUDA(template, "mask", mask+"-")
+			}
+		}
+		batch = append(batch, template)
+	}
+	return batch, nil
+}
+
+// hasPendingInstance reports whether the template has an open instance
+// other than the ones in except.
+func (m *App) hasPendingInstance(templateUUID string, except map[string]bool) bool {
+	for _, t := range m.todos {
+		if t.parent == templateUUID && !except[t.uuid] && !t.completed {
+			return true
+		}
+	}
+	return false
+}
+
+// stopRecurrence deletes the template so no more instances are generated.
+// Instances already generated are kept.
+func (m *App) stopRecurrence(template todo) tea.Cmd {
+	return m.confirm("Stop this task recurring?", []todo{template}, func() tea.Cmd {
+		return m.deleteTodos([]todo{template}, writeOpts{verb: "stopped recurrence of"})
+	})
+}
+
+// groupRecurring moves the instances of each template in todos to just
+// below it, soonest first.
+func groupRecurring(todos []todo) []todo {
+	templates := make(map[string]bool)
+	for _, t := range todos {
+		if t.recurring {
+			templates[t.uuid] = true
+		}
+	}
+	if len(templates) == 0 {
+		return todos
+	}
+
+	instances := make(map[string][]todo)
+	for _, t := range todos {
+		if templates[t.parent] {
+			instances[t.parent] = append(instances[t.parent], t)
+		}
+	}
+
+	grouped := make([]todo, 0, len(todos))
+	for _, t := range todos {
+		if templates[t.parent] {
+			continue
+		}
+		grouped = append(grouped, t)
+		if t.recurring {
+			children := instances[t.uuid]
+			sort.SliceStable(children, func(i, j int) bool {
+				return children[i].due < children[j].due
+			})
+			grouped = append(grouped, children...)
+		}
+	}
+	return grouped
+}
+
+type recurAction int
+
+const (
+	recurCompleteInstance recurAction = iota
+	recurStop
+)
+
+func (a recurAction) String() string {
+	if a == recurStop {
+		return "Stop recurrence"
+	}
+	return "Complete this instance"
+}
+
+func (m *App) startRecurMenu() {
+	selected, ok := m.selectedTodo()
+	if !ok || m.isPending(selected.uuid) {
+		return
+	}
+
+	m.recurOptions = nil
+	if selected.parent != "" && !selected.completed {
+		m.recurOptions = append(m.recurOptions, recurCompleteInstance)
+	}
+	if _, ok := m.recurringTemplate(selected); ok {
+		m.recurOptions = append(m.recurOptions, recurStop)
+	}
+	if len(m.recurOptions) == 0 {
+		return
+	}
+
+	m.recurMenuMode = true
+	m.recurUUID = selected.uuid
+	m.recurCursor = 0
+}
+
+func (m *App) handleRecurMenuKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		if m.recurCursor > 0 {
+			m.recurCursor--
+		}
+	case "down", "j":
+		if m.recurCursor < len(m.recurOptions)-1 {
+			m.recurCursor++
+		}
+	case "enter", " ":
+		m.recurMenuMode = false
+		i := m.todoIndex(m.recurUUID)
+		if i < 0 || m.isPending(m.recurUUID) {
+			return nil
+		}
+		selected := m.todos[i]
+
+		switch m.recurOptions[m.recurCursor] {
+		case recurCompleteInstance:
+			return m.completeInstance(selected)
+		case recurStop:
+			if template, ok := m.recurringTemplate(selected); ok {
+				return m.stopRecurrence(template)
+			}
+		}
+	case "esc":
+		m.recurMenuMode = false
+	case "ctrl+c", "q":
+		return m.quit()
+	}
+	return nil
+}
+
+func (m *App) renderRecurMenu() string {
+	var items []string
+	for i, option := range m.recurOptions {
+		cursor := "  "
+		if i == m.recurCursor {
+			cursor = "❯ "
+		}
+
+		line := cursor + option.String()
+		if i == m.recurCursor {
+			line = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#1e1e2e")).
+				Background(lipgloss.Color("#f38ba8")).
+				Bold(true).
+				Render(line)
+		}
+		items = append(items, line)
+	}
+
+	heading := "Recurring Task:"
+	if i := m.todoIndex(m.recurUUID); i >= 0 {
+		if template, ok := m.recurringTemplate(m.todos[i]); ok {
+			heading = fmt.Sprintf("%s (%s):", template.text, template.recur)
+		}
+	}
+
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#fab387")).
+		Bold(true).
+		Render(heading)
+
+	instructions := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086")).
+		Render("Press enter to select, esc to cancel")
+
+	style := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#6c7086")).
+		Padding(1, 2).
+		Width(50)
+
+	return style.Render(title + "\n\n" + strings.Join(items, "\n") + "\n\n" + instructions)
+}
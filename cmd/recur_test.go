@@ -0,0 +1,134 @@
+package cmd
+
+import "testing"
+
+// addRecurring adds a weekly task and returns its template and first
+// instance as stored.
+func (h *appHarness) addRecurring(text string) (template, instance todo) {
+	h.t.Helper()
+	h.addTask(text + " due:2030-01-07 recur:weekly")
+	for _, t := range h.app.todos {
+		switch {
+		case t.recurring && t.text == text:
+			template = t
+		case t.parent != "" && t.text == text:
+			instance = t
+		}
+	}
+	if template.uuid == "" || instance.uuid == "" {
+		h.t.Fatalf("adding %q did not create a template and an instance", text)
+	}
+	return template, instance
+}
+
+// instancesOf returns the stored instances of template in mask order.
+func (h *appHarness) instancesOf(template todo) map[int]todo {
+	h.t.Helper()
+	instances := make(map[int]todo)
+	for _, t := range h.app.todos {
+		if t.parent != template.uuid {
+			continue
+		}
+		index, ok := instanceIndex(t)
+		if !ok {
+			h.t.Fatalf("instance %s has no imask", t.uuid)
+		}
+		instances[index] = t
+	}
+	return instances
+}
+
+func TestRecurringAdd(t *testing.T) {
+	h := newHarness(t)
+	template, instance := h.addRecurring("Water plants")
+
+	if mask := recurrenceMask(template); mask != "-" {
+		t.Errorf("template mask %q, want %q", mask, "-")
+	}
+	if index, ok := instanceIndex(instance); !ok || index != 0 {
+		t.Errorf("instance imask %d (%v), want 0", index, ok)
+	}
+	if task := h.stored(template.uuid); task == nil || task.Status != "recurring" {
+		t.Errorf("template stored as %+v", task)
+	}
+	if task := h.stored(instance.uuid); task == nil || task.Status != "pending" || task.Parent != template.uuid {
+		t.Errorf("instance stored as %+v", task)
+	}
+}
+
+func TestRecurringCompleteInstance(t *testing.T) {
+	h := newHarness(t, WithoutConfirmation())
+	template, instance := h.addRecurring("Water plants")
+
+	h.app.selectTodo(instance.uuid)
+	h.press("R", "enter")
+	h.settle()
+
+	if task := h.stored(instance.uuid); task.Status != "completed" {
+		t.Errorf("instance status %q, want completed", task.Status)
+	}
+	template, _ = h.app.recurringTemplate(h.app.todos[h.app.todoIndex(template.uuid)])
+	if mask := recurrenceMask(template); mask != "+-" {
+		t.Errorf("template mask %q, want %q", mask, "+-")
+	}
+
+	next, ok := h.instancesOf(template)[1]
+	if !ok {
+		t.Fatal("no instance generated at imask 1")
+	}
+	if next.completed || next.due != instance.due+7*24*60*60 {
+		t.Errorf("next instance due %d (completed %v), want %d", next.due, next.completed, instance.due+7*24*60*60)
+	}
+	if task := h.stored(next.uuid); task == nil || task.Parent != template.uuid {
+		t.Errorf("next instance stored as %+v", task)
+	}
+}
+
+func TestRecurringCompleteStopsAtUntil(t *testing.T) {
+	h := newHarness(t, WithoutConfirmation())
+	h.addTask("Pay rent due:2030-01-07 recur:weekly until:2030-01-10")
+
+	var template, instance todo
+	for _, t := range h.app.todos {
+		if t.recurring {
+			template = t
+		} else if t.parent != "" {
+			instance = t
+		}
+	}
+	h.run(h.app.completeInstance(instance))
+	h.settle()
+
+	template = h.app.todos[h.app.todoIndex(template.uuid)]
+	if mask := recurrenceMask(template); mask != "+" {
+		t.Errorf("template mask %q, want %q", mask, "+")
+	}
+	if instances := h.instancesOf(template); len(instances) != 1 {
+		t.Errorf("%d instances after the last one was completed, want 1", len(instances))
+	}
+}
+
+func TestRecurringBulkComplete(t *testing.T) {
+	h := newHarness(t, WithoutConfirmation())
+	plain := h.addTask("Buy milk")
+	template, instance := h.addRecurring("Water plants")
+
+	h.app.selected[plain.uuid] = struct{}{}
+	h.app.selected[instance.uuid] = struct{}{}
+	h.press("c")
+	h.settle()
+
+	if task := h.stored(plain.uuid); task.Status != "completed" {
+		t.Errorf("plain task status %q, want completed", task.Status)
+	}
+	if task := h.stored(instance.uuid); task.Status != "completed" {
+		t.Errorf("instance status %q, want completed", task.Status)
+	}
+	template = h.app.todos[h.app.todoIndex(template.uuid)]
+	if mask := recurrenceMask(template); mask != "+-" {
+		t.Errorf("template mask %q, want %q", mask, "+-")
+	}
+	if _, ok := h.instancesOf(template)[1]; !ok {
+		t.Error("no instance generated at imask 1")
+	}
+}
@@ -3,81 +3,149 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"regexp"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 
 	"github.com/EwanGreer/todolist/taskwarrior"
+	"github.com/EwanGreer/todolist/timelog"
 )
 
 type todo struct {
-	uuid      string
-	text      string
-	project   string
-	completed bool
-	createdAt int64
+	uuid        string
+	text        string
+	project     string
+	priority    string
+	due         int64
+	tags        []string
+	annotations []taskwarrior.Annotation
+	depends     []string
+	recur       string
+	until       int64
+	parent      string
+	recurring   bool
+	start       int64
+	completed   bool
+	createdAt   int64
+	task        *taskwarrior.Task
 }
 
 type App struct {
 	todos                []todo
 	table                table.Model
-	selected             map[int]struct{}
+	rowColors            []rowColors
+	tableOffset          int
+	selected             map[string]struct{}
 	currentFilter        string
 	projects             []string
 	searchMode           bool
 	searchText           string
 	projectSelectionMode bool
 	projectCursor        int
-	tw                   *taskwarrior.TaskWarrior
+	collapsedProjects    map[string]bool
+	tagFilter            []string
+	tagSelectionMode     bool
+	tagOptions           []string
+	tagCursor            int
+	tagChoices           map[string]bool
+	detailMode           bool
+	detailUUID           string
+	annotationCursor     int
+	annotationInputMode  bool
+	annotationText       string
+	dependsMode          bool
+	dependsUUID          string
+	dependsOptions       []todo
+	dependsCursor        int
+	dependsChoices       map[string]bool
+	recurMenuMode        bool
+	recurUUID            string
+	recurOptions         []recurAction
+	recurCursor          int
+	backend              taskwarrior.Backend
 	width                int
 	height               int
 	addMode              bool
 	addText              string
+	editMode             bool
+	editText             string
+	editUUID             string
+	status               *statusMessage
+	messages             []statusMessage
+	nextMessageID        int
+	logMode              bool
+	logOffset            int
+	visualMode           bool
+	visualAnchor         int
+	bulkInputMode        bool
+	bulkInputKind        bulkInputKind
+	bulkInputText        string
+	pending              map[string]pendingOp
+	timeWrites           int
+	quitting             bool
+	spinner              spinner.Model
+	spinning             bool
+	ticking              bool
+	timeLog              *timelog.Log
+	timeMode             bool
+	timeRange            timeRange
+	timeEntries          []timelog.Entry
+	focus                *focusSession
+	focusMode            bool
+	nextFocusID          int
+	focusWork            time.Duration
+	focusBreak           time.Duration
+	focusLongBreak       time.Duration
+	loading              bool
+	reloading            bool
+	reloadQueued         bool
+	loadErr              error
+	sortMode             sortMode
+	undoStack            []historyEntry
+	redoStack            []historyEntry
+	nextGroup            int
+	lastGroup            int
+	confirmDialog        *confirmDialog
+	skipConfirm          bool
 }
 
-func sortTodosByCreatedAt(todos []todo) {
-	sort.Slice(todos, func(i, j int) bool {
-		return todos[i].createdAt > todos[j].createdAt
-	})
-}
+type AppOption func(*App)
 
-func parseTaskWarriorInput(input string) (description string, project string) {
-	projectRegex := regexp.MustCompile(`project:(\w+)`)
-	projectMatch := projectRegex.FindStringSubmatch(input)
-
-	if len(projectMatch) > 1 {
-		project = projectMatch[1]
-		description = strings.TrimSpace(projectRegex.ReplaceAllString(input, ""))
-	} else {
-		description = strings.TrimSpace(input)
-		project = "default"
+// WithoutConfirmation runs deletes and bulk actions without asking first.
+func WithoutConfirmation() AppOption {
+	return func(m *App) {
+		m.skipConfirm = true
 	}
-
-	return description, project
 }
 
-func NewApp() *App {
-	tw, err := taskwarrior.New()
-	if err != nil {
-		fmt.Printf("Error initializing Taskwarrior: %v\n", err)
-		os.Exit(1)
-	}
+func sortTodosByCreatedAt(todos []todo) {
+	sort.SliceStable(todos, func(i, j int) bool {
+		if todos[i].createdAt != todos[j].createdAt {
+			return todos[i].createdAt > todos[j].createdAt
+		}
+		// Break ties so the order is the same on every render
+		return todos[i].uuid < todos[j].uuid
+	})
+}
 
-	todos := loadTodosFromTaskwarrior(tw)
+func NewApp(backend taskwarrior.Backend, opts ...AppOption) *App {
+	var todos []todo
 
 	projects := getUniqueProjects(todos)
 	projects = append([]string{"all"}, projects...)
 
 	columns := []table.Column{
 		{Title: "Status", Width: 8},
-		{Title: "Task", Width: 30},
-		{Title: "Project", Width: 15},
+		{Title: "Pri", Width: 5},
+		{Title: "Task", Width: 26},
+		{Title: "Due", Width: 15},
+		{Title: "Project", Width: 12},
 	}
 
 	rows := make([]table.Row, len(todos))
@@ -86,7 +154,10 @@ func NewApp() *App {
 		if todo.completed {
 			status = "[✓]"
 		}
-		rows[i] = table.Row{status, todo.text, todo.project}
+		if todo.recurring {
+			status = "[↻]"
+		}
+		rows[i] = table.Row{status, todo.priority, todo.text, "", todo.project}
 	}
 
 	t := table.New(
@@ -96,121 +167,39 @@ func NewApp() *App {
 		table.WithHeight(7),
 	)
 
-	s := table.DefaultStyles()
-	s.Header = s.Header.
-		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("#6c7086")).
-		BorderBottom(true).
-		Bold(true).
-		Foreground(lipgloss.Color("#fab387")).
-		PaddingLeft(1).
-		PaddingRight(1)
-	s.Selected = s.Selected.
-		Foreground(lipgloss.Color("#1e1e2e")).
-		Background(lipgloss.Color("#f38ba8")).
-		Bold(true)
-	s.Cell = s.Cell.
-		Foreground(lipgloss.Color("#cdd6f4")).
-		PaddingLeft(1).
-		PaddingRight(1)
-	t.SetStyles(s)
-
-	return &App{
+	t.SetStyles(tableStyles())
+
+	app := &App{
 		todos:                todos,
 		table:                t,
-		selected:             make(map[int]struct{}),
+		selected:             make(map[string]struct{}),
 		currentFilter:        "all",
 		projects:             projects,
 		searchMode:           false,
 		searchText:           "",
 		projectSelectionMode: false,
 		projectCursor:        0,
-		tw:                   tw,
+		collapsedProjects:    make(map[string]bool),
+		backend:              backend,
 		width:                80,
 		height:               24,
 		addMode:              false,
 		addText:              "",
+		pending:              make(map[string]pendingOp),
+		spinner:              spinner.New(spinner.WithSpinner(spinner.MiniDot)),
+		loading:              true,
+		focusWork:            defaultFocusWork,
+		focusBreak:           defaultFocusBreak,
+		focusLongBreak:       defaultFocusLongBreak,
 	}
-}
-
-func loadTodosFromTaskwarrior(tw *taskwarrior.TaskWarrior) []todo {
-	var todos []todo
-
-	// Load pending tasks
-	pendingTasks, err := tw.LoadPendingTasks()
-	if err != nil {
-		fmt.Printf("Warning: Could not load pending tasks: %v\n", err)
-	} else {
-		for _, task := range pendingTasks {
-			project := task.Project
-			if project == "" {
-				project = "default"
-			}
-			todos = append(todos, todo{
-				uuid:      task.UUID,
-				text:      task.Description,
-				project:   project,
-				completed: false,
-				createdAt: task.Entry,
-			})
-		}
-	}
-
-	// Load completed tasks
-	completedTasks, err := tw.LoadCompletedTasks()
-	if err != nil {
-		fmt.Printf("Warning: Could not load completed tasks: %v\n", err)
-	} else {
-		for _, task := range completedTasks {
-			project := task.Project
-			if project == "" {
-				project = "default"
-			}
-			todos = append(todos, todo{
-				uuid:      task.UUID,
-				text:      task.Description,
-				project:   project,
-				completed: true,
-				createdAt: task.Entry,
-			})
-		}
+	for _, opt := range opts {
+		opt(app)
 	}
-
-	// Sort the combined list by creation date (most recent first)
-	sortTodosByCreatedAt(todos)
-
-	return todos
-}
-
-func (m *App) saveTodoToTaskwarrior(t *todo) error {
-	task := &taskwarrior.Task{
-		UUID:        t.uuid,
-		Description: t.text,
-		Project:     t.project,
-		Status:      "pending",
-	}
-
-	if t.completed {
-		task.Status = "completed"
-	}
-
-	err := m.tw.SaveTask(task)
-	if err == nil && t.uuid == "" {
-		// Update the todo with the generated UUID
-		t.uuid = task.UUID
-	}
-	return err
-}
-
-func (m *App) deleteTodoFromTaskwarrior(t todo) error {
-	if t.uuid == "" {
-		return nil // Can't delete without UUID
-	}
-	return m.tw.DeleteTask(t.uuid)
+	return app
 }
 
 func (m *App) Init() tea.Cmd {
-	return nil
+	return tea.Batch(m.reload(), m.startSpinner())
 }
 
 func (m *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -221,32 +210,77 @@ func (m *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		return m, nil
+	case todosLoadedMsg:
+		return m, m.handleTodosLoaded(msg)
+	case todosSavedMsg:
+		return m, m.handleTodosSaved(msg)
+	case todosDeletedMsg:
+		return m, m.handleTodosDeleted(msg)
+	case statusExpiredMsg:
+		m.handleStatusExpired(msg)
+		return m, nil
+	case spinner.TickMsg:
+		return m, m.handleSpinnerTick(msg)
+	case activeTickMsg:
+		return m, m.handleActiveTick()
+	case focusTickMsg:
+		return m, m.handleFocusTick(msg)
+	case timeLoggedMsg:
+		return m, m.handleTimeLogged(msg)
+	case timeLogLoadedMsg:
+		return m, m.handleTimeLogLoaded(msg)
 	case tea.KeyMsg:
+		if m.quitting {
+			return m, m.handleQuittingKey(msg)
+		}
+
+		if m.confirmDialog != nil {
+			return m, m.handleConfirmKey(msg)
+		}
+
+		if m.focusMode {
+			return m, m.handleFocusKey(msg)
+		}
+
+		if m.timeMode {
+			return m, m.handleTimeViewKey(msg)
+		}
+
+		if m.logMode {
+			return m, m.handleMessageLogKey(msg)
+		}
+
+		if m.detailMode {
+			return m, m.handleDetailKey(msg)
+		}
+
 		if m.addMode {
 			switch msg.String() {
 			case "enter":
 				if strings.TrimSpace(m.addText) != "" {
-					description, project := parseTaskWarriorInput(m.addText)
+					// A new task has no tags to remove, so -word stays in the text
+					input, err := parseTaskWarriorInput(m.addText, nil)
+					if err != nil {
+						// Keep the form open so the input can be fixed
+						return m, m.notifyError(err)
+					}
 
 					newTodo := todo{
-						text:      description,
 						completed: false,
-						project:   project,
 						createdAt: time.Now().Unix(),
 					}
-
-					if err := m.saveTodoToTaskwarrior(&newTodo); err != nil {
-						fmt.Printf("Error saving new task: %v\n", err)
+					input.apply(&newTodo)
+					if newTodo.recur != "" {
+						cmd = m.addRecurring(newTodo)
 					} else {
-						m.reloadTodos()
-						m.updateTable()
+						cmd = m.addTodo(newTodo)
 					}
 				}
 
 				// Exit add mode
 				m.addMode = false
 				m.addText = ""
-				return m, nil
+				return m, cmd
 			case "esc":
 				m.addMode = false
 				m.addText = ""
@@ -256,8 +290,8 @@ func (m *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.addText = m.addText[:len(m.addText)-1]
 				}
 				return m, nil
-			case "ctrl+c", "q":
-				return m, tea.Quit
+			case "ctrl+c":
+				return m, m.quit()
 			default:
 				if len(msg.String()) == 1 {
 					m.addText += msg.String()
@@ -266,30 +300,28 @@ func (m *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		if m.editMode {
+			return m, m.handleEditKey(msg)
+		}
+
+		if m.bulkInputMode {
+			return m, m.handleBulkInputKey(msg)
+		}
+
+		if m.tagSelectionMode {
+			return m, m.handleTagSelectionKey(msg)
+		}
+
+		if m.dependsMode {
+			return m, m.handleDependsSelectionKey(msg)
+		}
+
+		if m.recurMenuMode {
+			return m, m.handleRecurMenuKey(msg)
+		}
+
 		if m.projectSelectionMode {
-			switch msg.String() {
-			case "up", "k":
-				if m.projectCursor > 0 {
-					m.projectCursor--
-				}
-				return m, nil
-			case "down", "j":
-				if m.projectCursor < len(m.projects)-1 {
-					m.projectCursor++
-				}
-				return m, nil
-			case "enter", " ":
-				m.currentFilter = m.projects[m.projectCursor]
-				m.projectSelectionMode = false
-				m.updateTable()
-				return m, nil
-			case "esc":
-				m.projectSelectionMode = false
-				return m, nil
-			case "ctrl+c", "q":
-				return m, tea.Quit
-			}
-			return m, nil
+			return m, m.handleProjectSelectionKey(msg)
 		}
 
 		if m.searchMode {
@@ -304,8 +336,8 @@ func (m *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.updateTable()
 				}
 				return m, nil
-			case "ctrl+c", "q":
-				return m, tea.Quit
+			case "ctrl+c":
+				return m, m.quit()
 			default:
 				if len(msg.String()) == 1 {
 					m.searchText += msg.String()
@@ -318,113 +350,137 @@ func (m *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Normal mode key handling
 		switch msg.String() {
 		case "ctrl+c", "q":
-			return m, tea.Quit
+			return m, m.quit()
 		case "/":
 			m.searchMode = true
 			return m, nil
 		case "esc":
-			if m.searchText != "" {
+			if m.hasMarks() {
+				m.clearMarks()
+			} else if m.searchText != "" {
 				m.searchText = ""
 				m.updateTable()
 			}
 			return m, nil
 		case "enter", " ":
-			filtered := m.getFilteredTodos()
-			if len(filtered) > 0 {
-				cursor := m.table.Cursor()
-				if cursor < len(filtered) {
-					targetTodo := filtered[cursor]
-					for i := range m.todos {
-						if m.todos[i].uuid == targetTodo.uuid || (m.todos[i].uuid == "" && m.todos[i].text == targetTodo.text && m.todos[i].project == targetTodo.project) {
-							// Toggle completion status
-							originalStatus := m.todos[i].completed
-							m.todos[i].completed = !m.todos[i].completed
-
-							// Save to Taskwarrior
-							if err := m.saveTodoToTaskwarrior(&m.todos[i]); err != nil {
-								fmt.Printf("Error saving task: %v\n", err)
-								// Revert the change if save failed
-								m.todos[i].completed = originalStatus
-							}
-							// Don't reload - just update the table with current state
-							break
-						}
-					}
-					m.updateTable()
+			if selected, ok := m.selectedTodo(); ok {
+				if selected.recurring || selected.parent != "" && !selected.completed {
+					// Recurring tasks offer completing the instance or stopping
+					m.startRecurMenu()
+					break
 				}
+				// Toggle completion status
+				selected.completed = !selected.completed
+				cmd = m.confirmComplete([]todo{selected}, func() tea.Cmd {
+					return m.updateTodo(selected)
+				})
 			}
 		case "d":
-			filtered := m.getFilteredTodos()
-			if len(filtered) > 0 {
-				cursor := m.table.Cursor()
-				if cursor < len(filtered) {
-					targetTodo := filtered[cursor]
-					for i := range m.todos {
-						if m.todos[i].uuid == targetTodo.uuid || (m.todos[i].uuid == "" && m.todos[i].text == targetTodo.text && m.todos[i].project == targetTodo.project) {
-							// Delete from Taskwarrior
-							if err := m.deleteTodoFromTaskwarrior(m.todos[i]); err != nil {
-								fmt.Printf("Error deleting task: %v\n", err)
-							} else {
-								// Reload todos from Taskwarrior to ensure consistency
-								m.reloadTodos()
-							}
-							break
-						}
-					}
-					m.updateTable()
-				}
+			if m.hasMarks() {
+				cmd = m.bulkDelete()
+			} else if selected, ok := m.selectedTodo(); ok {
+				cmd = m.confirm("Delete this task?", []todo{selected}, func() tea.Cmd {
+					return m.deleteTodo(selected)
+				})
 			}
+		case "m":
+			m.toggleMark()
+		case "V":
+			m.toggleVisual()
+		case "c":
+			cmd = m.bulkComplete()
+		case "M":
+			m.startBulkInput(bulkMove)
+		case "T":
+			m.startBulkInput(bulkTag)
+		case "u":
+			cmd = m.undo()
+		case "ctrl+r":
+			cmd = m.redo()
+		case "L":
+			m.openMessageLog()
+		case "R":
+			m.startRecurMenu()
+		case "s":
+			cmd = m.toggleStart()
+		case "w":
+			cmd = m.openTimeView()
+		case "p":
+			cmd = m.startFocus()
+		case "+":
+			cmd = m.shiftPriority(1)
+		case "-":
+			cmd = m.shiftPriority(-1)
+		case "o":
+			m.sortMode = m.sortMode.next()
+			m.updateTable()
 		case "a":
 			m.addMode = true
 			m.addText = ""
+		case "e":
+			m.startEdit()
 		case "f":
-			for i, project := range m.projects {
-				if project == m.currentFilter {
-					m.projectCursor = i
-					break
-				}
-			}
-			m.projectSelectionMode = true
+			m.startProjectSelection()
 		case "F":
 			m.prevFilter()
 			m.updateTable()
+		case "t":
+			m.startTagSelection()
+		case "i":
+			m.startDetail()
+		case "D":
+			m.startDependsSelection()
 		default:
 			m.table, cmd = m.table.Update(msg)
+			// Re-render so the visual range and the plain cursor row follow
+			// the cursor
+			m.updateTable()
 		}
 	}
 	return m, cmd
 }
 
 func (m App) View() string {
-	mainView := m.renderMainView()
-
-	centeredMainView := lipgloss.Place(
-		m.width, m.height,
-		lipgloss.Center, lipgloss.Center,
-		mainView,
-	)
-
-	if m.projectSelectionMode {
-		overlay := m.renderProjectSelection()
-
-		return lipgloss.Place(
-			m.width, m.height,
-			lipgloss.Center, lipgloss.Center,
-			overlay,
-		)
-	}
-
-	if m.addMode {
-		overlay := m.renderAddForm()
-
+	var overlay string
+	switch {
+	case m.confirmDialog != nil:
+		overlay = m.renderConfirmDialog()
+	case m.focusMode:
+		overlay = m.renderFocus()
+	case m.timeMode:
+		overlay = m.renderTimeView()
+	case m.logMode:
+		overlay = m.renderMessageLog()
+	case m.detailMode:
+		overlay = m.renderDetail()
+	case m.projectSelectionMode:
+		overlay = m.renderProjectSelection()
+	case m.tagSelectionMode:
+		overlay = m.renderTagSelection()
+	case m.dependsMode:
+		overlay = m.renderDependsSelection()
+	case m.recurMenuMode:
+		overlay = m.renderRecurMenu()
+	case m.addMode:
+		overlay = m.renderAddForm()
+	case m.editMode:
+		overlay = m.renderEditForm()
+	case m.bulkInputMode:
+		overlay = m.renderBulkInput()
+	default:
 		return lipgloss.Place(
 			m.width, m.height,
 			lipgloss.Center, lipgloss.Center,
-			overlay,
+			m.renderMainView(),
 		)
 	}
 
-	return centeredMainView
+	// Keep the status bar visible so errors from a form are not hidden by it
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(lipgloss.Center, overlay, m.renderStatusBar()),
+	)
 }
 
 func (m App) renderMainView() string {
@@ -446,44 +502,127 @@ func (m App) renderMainView() string {
 	}
 
 	headerInfo := filterInfo
+	if active := m.activeTodos(); len(active) > 0 {
+		activeInfo := fmt.Sprintf("Active: %s %s", active[0].text, formatElapsed(active[0].start, time.Now()))
+		if len(active) > 1 {
+			activeInfo += fmt.Sprintf(" (+%d more)", len(active)-1)
+		}
+		headerInfo += " • " + activeInfo
+	}
+	if focusInfo := m.focusInfo(); focusInfo != "" {
+		headerInfo += " • " + focusInfo
+	}
+	if m.loading {
+		headerInfo = m.spinner.View() + " Loading tasks • " + headerInfo
+	}
+	if m.quitting {
+		headerInfo = m.spinner.View() + " Saving… quitting once done (ctrl+c: quit now, esc: stay) • " + headerInfo
+	}
+	if len(m.tagFilter) > 0 {
+		headerInfo += " • Tags: " + formatTags(m.tagFilter)
+	}
 	if searchInfo != "" {
 		headerInfo += " • " + searchInfo
 	}
+	if location := m.backend.Location(); location != "" {
+		headerInfo += " • Data: " + location
+	}
+	if m.sortMode != sortByCreated {
+		headerInfo += " • Sort: " + m.sortMode.String()
+	}
+	if m.hasMarks() {
+		markInfo := fmt.Sprintf("%d marked", len(m.markedTodos()))
+		if m.visualMode {
+			markInfo = "VISUAL • " + markInfo
+		}
+		headerInfo += " • " + markInfo
+	}
 
 	headerStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#fab387")).
 		Bold(true).
 		Margin(0, 0, 1, 0)
 
-	helpText := "q: quit • ↑/↓: navigate • space/enter: toggle • a: add task • d: delete • f: filter • F: prev filter • /: search • esc: clear search"
+	helpText := "q: quit • ↑/↓: navigate • space/enter: toggle • a: add task • e: edit • i: details • D: depends • R: recurrence • s: start/stop • w: time • p: focus • d: delete • m: mark • V: visual • c: complete • M: move • T: tag • u: undo • ctrl+r: redo • +/-: priority • o: sort • L: messages • f: filter • F: prev filter • t: tags • /: search • esc: clear search"
 
 	helpStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#6c7086")).
 		Margin(1, 0)
 
 	return headerStyle.Render(headerInfo) + "\n" +
-		baseStyle.Render(m.table.View()) + "\n" +
+		baseStyle.Render(m.renderTable()) + "\n" +
+		m.renderStatusBar() + "\n" +
 		helpStyle.Render(helpText)
 }
 
 func (m *App) updateTable() {
 	filtered := m.getFilteredTodos()
-
-	// Sort filtered todos by creation date (most recent first)
-	sortTodosByCreatedAt(filtered)
+	now := time.Now()
+	blocking := m.blockingSet()
 
 	rows := make([]table.Row, len(filtered))
+	colors := make([]rowColors, len(filtered))
 	for i, todo := range filtered {
 		status := "[ ]"
 		if todo.completed {
 			status = "[✓]"
 		}
-		rows[i] = table.Row{status, todo.text, todo.project}
+		if todo.recurring {
+			status = "[↻]"
+		}
+		if len(todo.annotations) > 0 {
+			status += "✎"
+		}
+		if m.isMarked(todo.uuid) {
+			status = "●" + status
+		}
+		active := todo.start != 0 && !todo.completed
+		if active {
+			status = "▶ " + formatElapsed(todo.start, now)
+		}
+		if m.isPending(todo.uuid) {
+			status += " " + m.spinner.View()
+		}
+		blocked := len(m.blockers(todo)) > 0
+		text := todo.text
+		if blocking[todo.uuid] && !todo.completed {
+			text = "▸ " + text
+		}
+		if blocked {
+			text = "⊘ " + text
+		}
+		if len(todo.tags) > 0 {
+			text += " " + formatTags(todo.tags)
+		}
+		due := formatDue(todo.due, now)
+		switch {
+		case todo.recurring:
+			due = "↻ " + todo.recur
+		case todo.parent != "" && i > 0 && (filtered[i-1].uuid == todo.parent || filtered[i-1].parent == todo.parent):
+			// Instances are listed under their template
+			text = "└ " + text
+		case todo.parent != "" && m.todoIndex(todo.parent) >= 0:
+			text = "↻ " + text
+		}
+		rows[i] = table.Row{status, todo.priority, text, due, todo.project}
+
+		// Rows due today or overdue stand out and blocked rows are dimmed;
+		// priority keeps its own colour
+		rowColor := dueColor(todoDueState(todo, now))
+		if active {
+			rowColor = activeColor
+		}
+		if blocked {
+			// Dim tasks that cannot be started yet
+			rowColor = dimColor
+		}
+		colors[i] = rowColors{row: rowColor, priority: priorityColor(todo.priority)}
 	}
 
 	// Preserve cursor position and focus state
 	currentCursor := m.table.Cursor()
 	m.table.SetRows(rows)
+	m.rowColors = colors
 
 	// Always maintain focus
 	m.table.Focus()
@@ -501,19 +640,16 @@ func (m *App) updateTable() {
 		// No rows, reset cursor
 		m.table.SetCursor(0)
 	}
-}
 
-func getUniqueProjects(todos []todo) []string {
-	projectMap := make(map[string]bool)
-	for _, todo := range todos {
-		projectMap[todo.project] = true
+	// Scroll just far enough to keep the cursor in view
+	height := m.table.Height()
+	cursor := m.table.Cursor()
+	m.tableOffset = min(m.tableOffset, cursor, max(len(rows)-height, 0))
+	if cursor >= m.tableOffset+height {
+		m.tableOffset = cursor - height + 1
 	}
-
-	var projects []string
-	for project := range projectMap {
-		projects = append(projects, project)
-	}
-	return projects
+	// The table puts its cursor at -1 when it has no rows
+	m.tableOffset = max(m.tableOffset, 0)
 }
 
 func (m *App) matchesSearch(todo todo) bool {
@@ -523,34 +659,64 @@ func (m *App) matchesSearch(todo todo) bool {
 	searchLower := strings.ToLower(m.searchText)
 	textLower := strings.ToLower(todo.text)
 	projectLower := strings.ToLower(todo.project)
+	if strings.Contains(textLower, searchLower) || strings.Contains(projectLower, searchLower) {
+		return true
+	}
 
-	return strings.Contains(textLower, searchLower) || strings.Contains(projectLower, searchLower)
+	for _, tag := range todo.tags {
+		if strings.Contains(strings.ToLower(tag), strings.TrimPrefix(searchLower, "+")) {
+			return true
+		}
+	}
+	return false
 }
 
 func (m *App) getFilteredTodos() []todo {
 	var filtered []todo
 
 	for _, todo := range m.todos {
-		projectMatch := m.currentFilter == "all" || todo.project == m.currentFilter
+		projectMatch := inProject(todo.project, m.currentFilter)
 		textMatch := m.matchesSearch(todo)
 
-		if projectMatch && textMatch {
+		if projectMatch && textMatch && m.matchesTagFilter(todo) {
 			filtered = append(filtered, todo)
 		}
 	}
+
+	// Match the order rows are displayed in
+	sortTodos(filtered, m.sortMode)
+	filtered = groupRecurring(filtered)
+	activeFirst(filtered)
 	return filtered
 }
 
+// selectedTodo returns the todo under the table cursor.
+func (m *App) selectedTodo() (todo, bool) {
+	filtered := m.getFilteredTodos()
+
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(filtered) {
+		return todo{}, false
+	}
+	return filtered[cursor], true
+}
+
+// selectTodo moves the cursor to the row showing uuid, if it is shown.
+func (m *App) selectTodo(uuid string) {
+	for i, t := range m.getFilteredTodos() {
+		if t.uuid == uuid {
+			m.table.SetCursor(i)
+			m.updateTable()
+			return
+		}
+	}
+}
+
 func (m *App) updateProjects() {
 	m.projects = getUniqueProjects(m.todos)
 	m.projects = append([]string{"all"}, m.projects...)
 }
 
-func (m *App) reloadTodos() {
-	m.todos = loadTodosFromTaskwarrior(m.tw)
-	m.updateProjects()
-}
-
 func (m *App) nextFilter() {
 	for i, project := range m.projects {
 		if project == m.currentFilter {
@@ -573,56 +739,6 @@ func (m *App) prevFilter() {
 	}
 }
 
-func (m *App) renderProjectSelection() string {
-	var items []string
-	for i, project := range m.projects {
-		cursor := "  "
-		if i == m.projectCursor {
-			cursor = "❯ "
-		}
-
-		selected := " "
-		if project == m.currentFilter {
-			selected = "✓"
-		}
-
-		displayName := project
-		if project == "all" {
-			displayName = "all projects"
-		}
-
-		line := fmt.Sprintf("%s[%s] %s", cursor, selected, displayName)
-		if i == m.projectCursor {
-			line = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#1e1e2e")).
-				Background(lipgloss.Color("#f38ba8")).
-				Bold(true).
-				Render(line)
-		}
-
-		items = append(items, line)
-	}
-
-	content := strings.Join(items, "\n")
-
-	title := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#fab387")).
-		Bold(true).
-		Render("Select Project Filter:")
-
-	instructions := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6c7086")).
-		Render("Press enter to select, esc to cancel")
-
-	style := lipgloss.NewStyle().
-		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#6c7086")).
-		Padding(1, 2).
-		Width(35)
-
-	return style.Render(title + "\n\n" + content + "\n\n" + instructions)
-}
-
 func (m *App) renderAddForm() string {
 	title := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#fab387")).
@@ -642,13 +758,13 @@ func (m *App) renderAddForm() string {
 	// Instructions
 	instructions := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#6c7086")).
-		Render("Enter task description (use project:name for projects) • enter to save • esc to cancel")
+		Render("Enter task description (use project:name for projects, priority:H/M/L, due:date, recur:weekly, +tag) • enter to save • esc to cancel")
 
 	// Examples
 	examples := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#6c7086")).
 		Italic(true).
-		Render("Examples: \"Fix the bug\" or \"Write tests project:myapp\"")
+		Render("Examples: \"Fix the bug\" or \"Write tests project:myapp priority:H due:friday\"")
 
 	// Main container
 	style := lipgloss.NewStyle().
@@ -660,13 +776,43 @@ func (m *App) renderAddForm() string {
 	return style.Render(title + "\n\n" + inputField + "\n\n" + instructions + "\n\n" + examples)
 }
 
+var (
+	dataDir         string
+	noConfirm       bool
+	workLength      time.Duration
+	breakLength     time.Duration
+	longBreakLength time.Duration
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "todolist",
 	Short: "A beautiful terminal-based todo list application",
 	Long: `A beautiful and interactive terminal-based todo list application built with bubbletea.
 Features include project filtering, text search, and an intuitive table interface.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if _, err := tea.NewProgram(NewApp(), tea.WithAltScreen()).Run(); err != nil {
+		if err := checkFocusDurations(workLength, breakLength, longBreakLength); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var opts []taskwarrior.Option
+		if dataDir != "" {
+			opts = append(opts, taskwarrior.WithDataDir(dataDir))
+		}
+
+		tw, err := taskwarrior.New(opts...)
+		if err != nil {
+			fmt.Printf("Error initializing Taskwarrior: %v\n", err)
+			os.Exit(1)
+		}
+
+		appOpts := []AppOption{WithTimeLog(timelog.New(tw.Location()))}
+		if noConfirm {
+			appOpts = append(appOpts, WithoutConfirmation())
+		}
+		appOpts = append(appOpts, WithFocusDurations(workLength, breakLength, longBreakLength))
+
+		if _, err := tea.NewProgram(NewApp(tw, appOpts...), tea.WithAltScreen()).Run(); err != nil {
 			fmt.Printf("Error: %v", err)
 			os.Exit(1)
 		}
@@ -682,4 +828,9 @@ func Execute() {
 
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.PersistentFlags().StringVar(&dataDir, "data-dir", "", "Taskwarrior data directory (defaults to TASKDATA, then data.location in .taskrc, then ~/.task)")
+	rootCmd.Flags().DurationVar(&workLength, "focus-work", defaultFocusWork, "Length of a pomodoro in focus mode")
+	rootCmd.Flags().DurationVar(&breakLength, "focus-break", defaultFocusBreak, "Length of the short break between pomodoros")
+	rootCmd.Flags().DurationVar(&longBreakLength, "focus-long-break", defaultFocusLongBreak, "Length of the long break after every fourth pomodoro")
+	rootCmd.Flags().BoolVar(&noConfirm, "no-confirm", false, "Delete and apply bulk actions without asking for confirmation")
 }
@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/EwanGreer/todolist/taskwarrior"
+	"github.com/EwanGreer/todolist/taskwarrior/taskwarriortest"
+)
+
+// settleTimeout bounds how long a harness waits for storage writes to land.
+const settleTimeout = 5 * time.Second
+
+// appHarness drives an App the way tea.Program would: commands run in the
+// background and the messages they return are fed back through Update on
+// the test goroutine.
+type appHarness struct {
+	t    *testing.T
+	app  *App
+	fake *taskwarriortest.Taskwarrior
+	msgs chan tea.Msg
+	done chan struct{}
+	quit bool
+}
+
+func newHarness(t *testing.T, opts ...AppOption) *appHarness {
+	t.Helper()
+	tw, fake, err := taskwarriortest.NewTaskWarrior(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewTaskWarrior: %v", err)
+	}
+
+	h := &appHarness{
+		t:    t,
+		app:  NewApp(tw, opts...),
+		fake: fake,
+		msgs: make(chan tea.Msg, 64),
+		done: make(chan struct{}),
+	}
+	// Ticks still sleeping when the test ends are dropped
+	t.Cleanup(func() { close(h.done) })
+
+	h.run(h.app.Init())
+	h.settle()
+	return h
+}
+
+func (h *appHarness) run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	go func() {
+		select {
+		case h.msgs <- cmd():
+		case <-h.done:
+		}
+	}()
+}
+
+func (h *appHarness) update(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case nil:
+		return
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			h.run(cmd)
+		}
+		return
+	case tea.QuitMsg:
+		h.quit = true
+		return
+	}
+	_, cmd := h.app.Update(msg)
+	h.run(cmd)
+}
+
+// settle processes messages until the app is neither loading, reloading
+// nor waiting on a task or time log write.
+func (h *appHarness) settle() {
+	h.t.Helper()
+	deadline := time.After(settleTimeout)
+	for h.app.loading || h.app.reloading || h.app.writing() {
+		select {
+		case msg := <-h.msgs:
+			h.update(msg)
+		case <-deadline:
+			h.t.Fatalf("storage did not settle: %d writes pending", len(h.app.pending))
+		}
+	}
+}
+
+// waitForQuit processes messages until the app quits.
+func (h *appHarness) waitForQuit() {
+	h.t.Helper()
+	deadline := time.After(settleTimeout)
+	for !h.quit {
+		select {
+		case msg := <-h.msgs:
+			h.update(msg)
+		case <-deadline:
+			h.t.Fatal("app did not quit")
+		}
+	}
+}
+
+// press sends keys one at a time. Single characters are typed as runes and
+// anything longer is a named key such as "enter".
+func (h *appHarness) press(keys ...string) {
+	h.t.Helper()
+	for _, key := range keys {
+		h.update(keyMsg(key))
+	}
+}
+
+func (h *appHarness) typeText(text string) {
+	h.t.Helper()
+	for _, r := range text {
+		h.press(string(r))
+	}
+}
+
+func keyMsg(key string) tea.KeyMsg {
+	switch key {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEscape}
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	case "ctrl+c":
+		return tea.KeyMsg{Type: tea.KeyCtrlC}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// stored returns the task the fake holds under uuid.
+func (h *appHarness) stored(uuid string) *taskwarrior.Task {
+	h.t.Helper()
+	tasks, err := h.fake.Tasks()
+	if err != nil {
+		h.t.Fatalf("Tasks: %v", err)
+	}
+	for _, task := range tasks {
+		if task.UUID == uuid {
+			return task
+		}
+	}
+	return nil
+}
+
+// addTask adds a task through the add form and returns the row it added.
+func (h *appHarness) addTask(text string) todo {
+	h.t.Helper()
+	shown := make(map[string]bool)
+	for _, t := range h.app.todos {
+		shown[t.uuid] = true
+	}
+
+	h.press("a")
+	h.typeText(text)
+	h.press("enter")
+	h.settle()
+
+	for _, t := range h.app.todos {
+		if !shown[t.uuid] {
+			return t
+		}
+	}
+	h.t.Fatalf("adding %q did not add a task", text)
+	return todo{}
+}
+
+func TestAppAdd(t *testing.T) {
+	h := newHarness(t)
+
+	added := h.addTask("Buy milk project:home priority:H +errand")
+	if added.text != "Buy milk" || added.project != "home" || added.priority != "H" {
+		t.Errorf("added %q in %q with priority %q", added.text, added.project, added.priority)
+	}
+
+	task := h.stored(added.uuid)
+	if task == nil {
+		t.Fatalf("task %s was not saved", added.uuid)
+	}
+	if task.Description != "Buy milk" || task.Project != "home" || task.Status != "pending" || !task.HasTag("errand") {
+		t.Errorf("saved %+v", task)
+	}
+	if h.app.isPending(added.uuid) {
+		t.Error("task still marked pending after the write landed")
+	}
+}
+
+func TestAppToggle(t *testing.T) {
+	h := newHarness(t)
+	added := h.addTask("Write report")
+
+	h.press(" ")
+	h.settle()
+	if task := h.stored(added.uuid); task.Status != "completed" || task.End == 0 {
+		t.Errorf("after completing: status %q, end %d", task.Status, task.End)
+	}
+
+	h.press(" ")
+	h.settle()
+	if task := h.stored(added.uuid); task.Status != "pending" || task.End != 0 {
+		t.Errorf("after reopening: status %q, end %d", task.Status, task.End)
+	}
+}
+
+func TestAppDelete(t *testing.T) {
+	h := newHarness(t)
+	added := h.addTask("Old idea")
+
+	h.press("d")
+	if h.app.confirmDialog == nil {
+		t.Fatal("delete did not ask for confirmation")
+	}
+	h.press("n")
+	if task := h.stored(added.uuid); task.Status != "pending" {
+		t.Fatalf("declining deleted the task: status %q", task.Status)
+	}
+
+	h.press("d", "y")
+	h.settle()
+	if task := h.stored(added.uuid); task.Status != "deleted" {
+		t.Errorf("status = %q, want deleted", task.Status)
+	}
+	if h.app.todoIndex(added.uuid) >= 0 {
+		t.Error("deleted task is still listed")
+	}
+}
+
+func TestAppReloadsAfterWrite(t *testing.T) {
+	h := newHarness(t)
+	if _, err := h.fake.Run(context.Background(), nil, "add", "Added elsewhere"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	h.addTask("Added here")
+	var texts []string
+	for _, t := range h.app.todos {
+		texts = append(texts, t.text)
+	}
+	if !slices.Contains(texts, "Added elsewhere") || !slices.Contains(texts, "Added here") {
+		t.Errorf("listed %q after a write, want both tasks", texts)
+	}
+}
+
+func TestAppWarnsAboutRowsStillSaving(t *testing.T) {
+	h := newHarness(t, WithoutConfirmation())
+	first := h.addTask("Write report")
+	second := h.addTask("Send report")
+
+	// The toggle of second is still in flight when both are deleted
+	h.app.selectTodo(second.uuid)
+	h.press(" ")
+	h.app.selected[first.uuid] = struct{}{}
+	h.app.selected[second.uuid] = struct{}{}
+	h.press("d")
+	if h.app.status == nil || h.app.status.severity != severityWarning {
+		t.Errorf("deleting a row still saving showed %+v, want a warning", h.app.status)
+	}
+	h.settle()
+
+	if task := h.stored(first.uuid); task.Status != "deleted" {
+		t.Errorf("first task status %q, want deleted", task.Status)
+	}
+	if task := h.stored(second.uuid); task.Status != "completed" {
+		t.Errorf("second task status %q, want completed", task.Status)
+	}
+}
+
+func TestAppUndo(t *testing.T) {
+	h := newHarness(t)
+	added := h.addTask("Call the bank")
+
+	h.press(" ")
+	h.settle()
+	h.press("u")
+	h.settle()
+	if task := h.stored(added.uuid); task.Status != "pending" {
+		t.Errorf("undoing the toggle left status %q", task.Status)
+	}
+
+	h.press("d", "y")
+	h.settle()
+	h.press("u")
+	h.settle()
+	if task := h.stored(added.uuid); task.Status != "pending" {
+		t.Errorf("undoing the delete left status %q", task.Status)
+	}
+	if h.app.todoIndex(added.uuid) < 0 {
+		t.Error("undone delete is not listed")
+	}
+
+	h.press("ctrl+r")
+	h.settle()
+	if task := h.stored(added.uuid); task.Status != "deleted" {
+		t.Errorf("redoing the delete left status %q", task.Status)
+	}
+}
+
+func TestAppUndoWhileSaving(t *testing.T) {
+	h := newHarness(t)
+	added := h.addTask("Call the bank")
+
+	// The toggle is still in flight when undo is pressed
+	h.press(" ", "u")
+	if h.app.status == nil || h.app.status.severity != severityWarning {
+		t.Errorf("undo while saving showed %+v, want a warning", h.app.status)
+	}
+	h.settle()
+	if len(h.app.undoStack) != 2 {
+		t.Fatalf("undo stack holds %d entries after the refused undo, want 2", len(h.app.undoStack))
+	}
+
+	h.press("u")
+	h.settle()
+	if task := h.stored(added.uuid); task.Status != "pending" {
+		t.Errorf("undoing the toggle left status %q", task.Status)
+	}
+
+	h.press("ctrl+r", "ctrl+r")
+	h.settle()
+	if len(h.app.redoStack) != 0 {
+		t.Errorf("redo stack holds %d entries, want 0", len(h.app.redoStack))
+	}
+	if task := h.stored(added.uuid); task.Status != "completed" {
+		t.Errorf("redoing the toggle left status %q", task.Status)
+	}
+}
+
+func TestAppUndoAdd(t *testing.T) {
+	h := newHarness(t)
+	added := h.addTask("Mistake")
+
+	h.press("u")
+	h.settle()
+	if task := h.stored(added.uuid); task.Status != "deleted" {
+		t.Errorf("undoing the add left status %q", task.Status)
+	}
+	if len(h.app.todos) != 0 {
+		t.Errorf("%d tasks listed after undoing the only add", len(h.app.todos))
+	}
+}
+
+func TestAppQuitWaitsForWrites(t *testing.T) {
+	h := newHarness(t)
+
+	h.press("a")
+	h.typeText("Buy milk")
+	h.press("enter", "ctrl+c")
+	if h.quit {
+		t.Fatal("quit before the add was saved")
+	}
+	if !h.app.quitting {
+		t.Fatal("quit was not deferred")
+	}
+
+	h.settle()
+	h.waitForQuit()
+
+	tasks, err := h.fake.Tasks()
+	if err != nil {
+		t.Fatalf("Tasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Description != "Buy milk" {
+		t.Errorf("stored %+v, want the added task", tasks)
+	}
+}
+
+func TestAppQuitWithoutWrites(t *testing.T) {
+	h := newHarness(t)
+	h.press("q")
+	h.waitForQuit()
+}
+
+func TestAppAddTypesQ(t *testing.T) {
+	h := newHarness(t)
+
+	added := h.addTask("Quarterly review due:tomorrow recur:quarterly")
+	if h.quit || h.app.quitting {
+		t.Fatal("typing q in the add form quit")
+	}
+	if template, ok := h.app.recurringTemplate(added); !ok || template.recur != "quarterly" {
+		t.Errorf("added %+v, want a quarterly recurrence", added)
+	}
+
+	h.press("/")
+	h.typeText("quarterly")
+	if h.quit || h.app.quitting || h.app.searchText != "quarterly" {
+		t.Errorf("typing q in the search quit or was lost: %q", h.app.searchText)
+	}
+}
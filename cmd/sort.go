@@ -0,0 +1,41 @@
+package cmd
+
+import "sort"
+
+type sortMode int
+
+const (
+	sortByCreated sortMode = iota
+	sortByPriority
+	sortByDue
+)
+
+var sortModeNames = []string{"created", "priority", "due"}
+
+func (s sortMode) String() string {
+	return sortModeNames[s]
+}
+
+func (s sortMode) next() sortMode {
+	return (s + 1) % sortMode(len(sortModeNames))
+}
+
+// sortTodos orders todos for display. Every mode falls back to creation
+// order, newest first, so rows keep their places between renders.
+func sortTodos(todos []todo, mode sortMode) {
+	sortTodosByCreatedAt(todos)
+	switch mode {
+	case sortByPriority:
+		sort.SliceStable(todos, func(i, j int) bool {
+			return priorityRank(todos[i].priority) > priorityRank(todos[j].priority)
+		})
+	case sortByDue:
+		// Soonest first, with undated tasks last
+		sort.SliceStable(todos, func(i, j int) bool {
+			if todos[i].due == 0 || todos[j].due == 0 {
+				return todos[j].due == 0 && todos[i].due != 0
+			}
+			return todos[i].due < todos[j].due
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxMessages bounds the message log kept for the session.
+const maxMessages = 200
+
+type severity int
+
+const (
+	severityInfo severity = iota
+	severitySuccess
+	severityWarning
+	severityError
+)
+
+// ttl is how long a message stays in the status bar. Problems stay up
+// longer so they can be read; all of them remain in the message log.
+func (s severity) ttl() time.Duration {
+	switch s {
+	case severityWarning:
+		return 8 * time.Second
+	case severityError:
+		return 15 * time.Second
+	}
+	return 4 * time.Second
+}
+
+func (s severity) icon() string {
+	switch s {
+	case severitySuccess:
+		return "✓"
+	case severityWarning:
+		return "!"
+	case severityError:
+		return "✗"
+	}
+	return "•"
+}
+
+func (s severity) color() lipgloss.Color {
+	switch s {
+	case severitySuccess:
+		return lipgloss.Color("#a6e3a1")
+	case severityWarning:
+		return lipgloss.Color("#f9e2af")
+	case severityError:
+		return lipgloss.Color("#f38ba8")
+	}
+	return lipgloss.Color("#89b4fa")
+}
+
+type statusMessage struct {
+	id       int
+	severity severity
+	text     string
+	at       time.Time
+}
+
+// statusExpiredMsg clears the status bar if it still shows message id.
+type statusExpiredMsg struct {
+	id int
+}
+
+// notify shows text in the status bar, adds it to the message log and
+// schedules it to be cleared.
+func (m *App) notify(sev severity, text string) tea.Cmd {
+	m.nextMessageID++
+	msg := statusMessage{id: m.nextMessageID, severity: sev, text: text, at: time.Now()}
+
+	m.messages = append(m.messages, msg)
+	if len(m.messages) > maxMessages {
+		m.messages = m.messages[len(m.messages)-maxMessages:]
+	}
+	m.status = &msg
+
+	return tea.Tick(sev.ttl(), func(time.Time) tea.Msg {
+		return statusExpiredMsg{id: msg.id}
+	})
+}
+
+func (m *App) notifyError(err error) tea.Cmd {
+	return m.notify(severityError, err.Error())
+}
+
+func (m *App) handleStatusExpired(msg statusExpiredMsg) {
+	if m.status != nil && m.status.id == msg.id {
+		m.status = nil
+	}
+}
+
+// renderStatusBar shows the latest message, or when it has expired the
+// error from the last load, which stays until a load succeeds.
+func (m App) renderStatusBar() string {
+	switch {
+	case m.status != nil:
+		return lipgloss.NewStyle().
+			Foreground(m.status.severity.color()).
+			Render(m.status.severity.icon() + " " + m.status.text)
+	case m.loadErr != nil:
+		return lipgloss.NewStyle().
+			Foreground(severityError.color()).
+			Render(severityError.icon() + " " + m.loadErr.Error())
+	}
+	return ""
+}
+
+func (m *App) openMessageLog() {
+	m.logMode = true
+	m.logOffset = 0
+}
+
+func (m *App) handleMessageLogKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		if m.logOffset > 0 {
+			m.logOffset--
+		}
+	case "down", "j":
+		if m.logOffset < len(m.messages)-m.logHeight() {
+			m.logOffset++
+		}
+	case "esc", "L", "q":
+		m.logMode = false
+	case "ctrl+c":
+		return m.quit()
+	}
+	return nil
+}
+
+// logHeight is how many messages the log overlay shows at once.
+func (m App) logHeight() int {
+	return max(m.height-12, 5)
+}
+
+func (m App) renderMessageLog() string {
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#fab387")).
+		Bold(true).
+		Render(fmt.Sprintf("Messages (%d)", len(m.messages)))
+
+	timeStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086"))
+
+	var lines []string
+	// Newest first
+	for i := len(m.messages) - 1 - m.logOffset; i >= 0 && len(lines) < m.logHeight(); i-- {
+		msg := m.messages[i]
+		text := lipgloss.NewStyle().
+			Foreground(msg.severity.color()).
+			Render(msg.severity.icon() + " " + msg.text)
+		lines = append(lines, timeStyle.Render(msg.at.Format("15:04:05"))+" "+text)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, timeStyle.Render("No messages yet"))
+	}
+
+	instructions := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086")).
+		Render("↑/↓ to scroll • esc to close")
+
+	style := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#6c7086")).
+		Padding(1, 2).
+		Width(min(max(m.width-10, 40), 100))
+
+	return style.Render(title + "\n\n" + strings.Join(lines, "\n") + "\n\n" + instructions)
+}
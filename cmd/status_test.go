@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/EwanGreer/todolist/taskwarrior"
+)
+
+// failingLoads fails every pending task load while err is set.
+type failingLoads struct {
+	taskwarrior.Backend
+	err error
+}
+
+func (b *failingLoads) LoadPendingTasksContext(ctx context.Context) ([]*taskwarrior.Task, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.Backend.LoadPendingTasksContext(ctx)
+}
+
+func TestLoadErrorStaysUntilLoaded(t *testing.T) {
+	h := newHarness(t)
+	backend := &failingLoads{Backend: h.app.backend, err: errors.New("data file locked")}
+	h.app.backend = backend
+
+	h.addTask("Buy milk")
+	if h.app.status == nil {
+		t.Fatal("no message after a failed load")
+	}
+	h.app.handleStatusExpired(statusExpiredMsg{id: h.app.status.id})
+	if bar := h.app.renderStatusBar(); !strings.Contains(bar, "data file locked") {
+		t.Errorf("status bar %q after the message expired, want the load error", bar)
+	}
+
+	backend.err = nil
+	h.addTask("Buy bread")
+	h.app.handleStatusExpired(statusExpiredMsg{id: h.app.nextMessageID})
+	if bar := h.app.renderStatusBar(); bar != "" {
+		t.Errorf("status bar %q after a successful load, want it empty", bar)
+	}
+}
@@ -0,0 +1,656 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/EwanGreer/todolist/taskwarrior"
+	"github.com/EwanGreer/todolist/timelog"
+)
+
+// storageTimeout bounds every backend call made from the TUI, so a hung
+// `task` process surfaces as an error instead of freezing the program.
+const storageTimeout = 10 * time.Second
+
+type opKind int
+
+const (
+	opAdd opKind = iota
+	opUpdate
+	opDelete
+)
+
+// pendingOp is a change already applied to App.todos whose backend write has
+// not landed yet. prev is what to restore if the write fails.
+type pendingOp struct {
+	kind opKind
+	prev todo
+}
+
+type todosLoadedMsg struct {
+	todos []todo
+	err   error
+}
+
+// writeOpts travels with a batch write. verb, when set, names a bulk action
+// to summarise once the batch lands; origin says which history stack the
+// write is recorded on, and group ties together the writes of an undo or
+// redo that both adds and removes tasks.
+type writeOpts struct {
+	verb   string
+	origin historyOrigin
+	group  int
+}
+
+// todosSavedMsg and todosDeletedMsg report a batch write. failed holds the
+// UUIDs that were not written.
+type todosSavedMsg struct {
+	todos  []todo
+	failed map[string]error
+	opts   writeOpts
+}
+
+type todosDeletedMsg struct {
+	todos  []todo
+	failed map[string]error
+	opts   writeOpts
+}
+
+func loadTodosCmd(backend taskwarrior.Backend) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+		defer cancel()
+
+		todos, err := loadTodosFromTaskwarrior(ctx, backend)
+		return todosLoadedMsg{todos: todos, err: err}
+	}
+}
+
+func saveTodosCmd(backend taskwarrior.Backend, todos []todo, opts writeOpts) tea.Cmd {
+	tasks := make([]*taskwarrior.Task, len(todos))
+	for i := range todos {
+		tasks[i] = taskFromTodo(&todos[i])
+	}
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+		defer cancel()
+
+		failed := batchFailures(backend.SaveTasksContext(ctx, tasks), taskUUIDs(tasks))
+
+		saved := make([]todo, len(tasks))
+		for i, task := range tasks {
+			if _, ok := failed[task.UUID]; ok {
+				saved[i] = todos[i]
+			} else {
+				saved[i] = todoFromTask(task)
+			}
+		}
+		return todosSavedMsg{todos: saved, failed: failed, opts: opts}
+	}
+}
+
+func deleteTodosCmd(backend taskwarrior.Backend, todos []todo, opts writeOpts) tea.Cmd {
+	uuids := make([]string, len(todos))
+	for i, t := range todos {
+		uuids[i] = t.uuid
+	}
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+		defer cancel()
+
+		failed := batchFailures(backend.DeleteTasksContext(ctx, uuids), uuids)
+		return todosDeletedMsg{todos: todos, failed: failed, opts: opts}
+	}
+}
+
+// batchFailures maps a batch write error to the UUIDs it affected. Errors
+// that are not a *taskwarrior.BatchError are taken to affect all of them.
+func batchFailures(err error, uuids []string) map[string]error {
+	if err == nil {
+		return nil
+	}
+
+	var batchErr *taskwarrior.BatchError
+	if errors.As(err, &batchErr) {
+		return batchErr.Failed
+	}
+
+	failed := make(map[string]error, len(uuids))
+	for _, uuid := range uuids {
+		failed[uuid] = err
+	}
+	return failed
+}
+
+func taskUUIDs(tasks []*taskwarrior.Task) []string {
+	uuids := make([]string, len(tasks))
+	for i, task := range tasks {
+		uuids[i] = task.UUID
+	}
+	return uuids
+}
+
+func loadTodosFromTaskwarrior(ctx context.Context, backend taskwarrior.Backend) ([]todo, error) {
+	var todos []todo
+	var errs []error
+
+	// Load pending tasks
+	pendingTasks, err := backend.LoadPendingTasksContext(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("could not load pending tasks: %w", err))
+	} else {
+		for _, task := range pendingTasks {
+			todos = append(todos, todoFromTask(task))
+		}
+	}
+
+	// Load completed tasks
+	completedTasks, err := backend.LoadCompletedTasksContext(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("could not load completed tasks: %w", err))
+	} else {
+		for _, task := range completedTasks {
+			todos = append(todos, todoFromTask(task))
+		}
+	}
+
+	// Load the templates recurring tasks are generated from
+	recurringTasks, err := backend.LoadRecurringTasksContext(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("could not load recurring tasks: %w", err))
+	} else {
+		for _, task := range recurringTasks {
+			todos = append(todos, todoFromTask(task))
+		}
+	}
+
+	// Sort the combined list by creation date (most recent first)
+	sortTodosByCreatedAt(todos)
+
+	return todos, errors.Join(errs...)
+}
+
+func todoFromTask(task *taskwarrior.Task) todo {
+	project := task.Project
+	if project == "" {
+		project = "default"
+	}
+	return todo{
+		uuid:        task.UUID,
+		text:        task.Description,
+		project:     project,
+		priority:    task.Priority,
+		due:         task.Due,
+		tags:        task.Tags,
+		annotations: task.Annotations,
+		depends:     task.Depends,
+		recur:       task.Recur,
+		until:       task.Until,
+		parent:      task.Parent,
+		start:       task.Start,
+		recurring:   task.Status == "recurring",
+		completed:   task.Status == "completed",
+		createdAt:   task.Entry,
+		task:        task,
+	}
+}
+
+// taskFromTodo applies the todo's editable fields to a copy of the task it
+// was loaded from, so attributes the TUI does not show are preserved.
+func taskFromTodo(t *todo) *taskwarrior.Task {
+	task := &taskwarrior.Task{}
+	if t.task != nil {
+		task = t.task.Clone()
+	}
+
+	task.UUID = t.uuid
+	task.Description = t.text
+	task.Project = t.project
+	if t.project == "default" {
+		task.Project = ""
+	}
+	task.Priority = t.priority
+	task.Due = t.due
+	task.Tags = append([]string(nil), t.tags...)
+	task.Annotations = append([]taskwarrior.Annotation(nil), t.annotations...)
+	task.Depends = append([]string(nil), t.depends...)
+	task.Recur = t.recur
+	task.Until = t.until
+	task.Parent = t.parent
+	task.Start = t.start
+
+	task.Status = "pending"
+	switch {
+	case t.completed:
+		task.Status = "completed"
+		// Completing a task stops it, as `task done` does
+		task.Start = 0
+	case t.recurring:
+		task.Status = "recurring"
+	}
+	return task
+}
+
+// addTodo shows t straight away and saves it in the background.
+func (m *App) addTodo(t todo) tea.Cmd {
+	return m.addTodos([]todo{t}, writeOpts{})
+}
+
+// addTodos inserts todos that are not shown yet, either new ones or deleted
+// ones being restored, and saves them as one batch.
+func (m *App) addTodos(todos []todo, opts writeOpts) tea.Cmd {
+	var batch []todo
+	skipped := 0
+	for _, t := range todos {
+		if t.uuid == "" {
+			uuid, err := taskwarrior.NewUUID()
+			if err != nil {
+				return m.notifyError(fmt.Errorf("could not save task: %w", err))
+			}
+			t.uuid = uuid
+		}
+		if m.isPending(t.uuid) {
+			skipped++
+			continue
+		}
+		if m.todoIndex(t.uuid) >= 0 {
+			continue
+		}
+
+		m.pending[t.uuid] = pendingOp{kind: opAdd}
+		m.todos = append(m.todos, t)
+		batch = append(batch, t)
+	}
+	if len(batch) == 0 {
+		return m.notifySkipped(skipped)
+	}
+
+	m.refresh()
+	return tea.Batch(saveTodosCmd(m.backend, batch, opts), m.startSpinner(), m.notifySkipped(skipped))
+}
+
+// updateTodo replaces the todo with the same UUID by t and saves it in the
+// background.
+func (m *App) updateTodo(t todo) tea.Cmd {
+	return m.updateTodos([]todo{t}, writeOpts{})
+}
+
+// updateTodos replaces each todo with the same UUID and saves them as one
+// batch. Rows that are still being written are left alone, with a warning.
+func (m *App) updateTodos(todos []todo, opts writeOpts) tea.Cmd {
+	var batch []todo
+	skipped := 0
+	for _, t := range todos {
+		if m.isPending(t.uuid) {
+			skipped++
+			continue
+		}
+		i := m.todoIndex(t.uuid)
+		if i < 0 {
+			continue
+		}
+
+		m.pending[t.uuid] = pendingOp{kind: opUpdate, prev: m.todos[i]}
+		m.todos[i] = t
+		batch = append(batch, t)
+	}
+	if len(batch) == 0 {
+		return m.notifySkipped(skipped)
+	}
+
+	m.refresh()
+	return tea.Batch(saveTodosCmd(m.backend, batch, opts), m.startSpinner(), m.notifySkipped(skipped))
+}
+
+// saveTodos writes todos as one batch, adding the ones not shown yet and
+// replacing the rest, so a change spanning several tasks is undone as one.
+func (m *App) saveTodos(todos []todo, opts writeOpts) tea.Cmd {
+	var batch []todo
+	skipped := 0
+	for _, t := range todos {
+		if m.isPending(t.uuid) {
+			skipped++
+			continue
+		}
+
+		if i := m.todoIndex(t.uuid); i >= 0 {
+			m.pending[t.uuid] = pendingOp{kind: opUpdate, prev: m.todos[i]}
+			m.todos[i] = t
+		} else {
+			m.pending[t.uuid] = pendingOp{kind: opAdd}
+			m.todos = append(m.todos, t)
+		}
+		batch = append(batch, t)
+	}
+	if len(batch) == 0 {
+		return m.notifySkipped(skipped)
+	}
+
+	m.refresh()
+	return tea.Batch(saveTodosCmd(m.backend, batch, opts), m.startSpinner(), m.notifySkipped(skipped))
+}
+
+// deleteTodo hides t straight away and deletes it in the background.
+func (m *App) deleteTodo(t todo) tea.Cmd {
+	return m.deleteTodos([]todo{t}, writeOpts{})
+}
+
+func (m *App) deleteTodos(todos []todo, opts writeOpts) tea.Cmd {
+	var batch []todo
+	skipped := 0
+	for _, t := range todos {
+		if m.isPending(t.uuid) {
+			skipped++
+			continue
+		}
+		i := m.todoIndex(t.uuid)
+		if i < 0 {
+			continue
+		}
+
+		m.pending[t.uuid] = pendingOp{kind: opDelete, prev: m.todos[i]}
+		m.todos = append(m.todos[:i], m.todos[i+1:]...)
+		batch = append(batch, t)
+	}
+	if len(batch) == 0 {
+		return m.notifySkipped(skipped)
+	}
+
+	m.refresh()
+	return tea.Batch(deleteTodosCmd(m.backend, batch, opts), m.startSpinner(), m.notifySkipped(skipped))
+}
+
+// notifySkipped warns that n tasks were left out of a change because an
+// earlier write to them has not landed yet.
+func (m *App) notifySkipped(n int) tea.Cmd {
+	if n == 0 {
+		return nil
+	}
+	return m.notify(severityWarning, fmt.Sprintf("Skipped %s still being saved: try again once saved", pluralTasks(n)))
+}
+
+func (m *App) isPending(uuid string) bool {
+	_, ok := m.pending[uuid]
+	return ok
+}
+
+// revert undoes the optimistic change recorded for uuid.
+func (m *App) revert(uuid string, op pendingOp) {
+	i := m.todoIndex(uuid)
+	switch op.kind {
+	case opAdd:
+		if i >= 0 {
+			m.todos = append(m.todos[:i], m.todos[i+1:]...)
+		}
+	case opUpdate:
+		if i >= 0 {
+			m.todos[i] = op.prev
+		}
+	case opDelete:
+		if i < 0 {
+			m.todos = append(m.todos, op.prev)
+		}
+	}
+}
+
+// reload reads every task back from the backend, so what Taskwarrior
+// stored and changes made outside the TUI replace the optimistic rows. A
+// reload asked for while one is in flight runs once that one returns.
+func (m *App) reload() tea.Cmd {
+	if m.reloading {
+		m.reloadQueued = true
+		return nil
+	}
+	m.reloading = true
+	return loadTodosCmd(m.backend)
+}
+
+func (m *App) handleTodosLoaded(msg todosLoadedMsg) tea.Cmd {
+	m.reloading = false
+	if m.reloadQueued {
+		// A write landed after this load read the backend
+		m.reloadQueued = false
+		return m.reload()
+	}
+	if msg.err != nil && !m.loading {
+		// A failed reload may have read only some statuses; keep what is
+		// shown rather than dropping the rest
+		m.loadErr = msg.err
+		return m.notifyError(msg.err)
+	}
+
+	// Writes still in flight win over what was just read back
+	local := make(map[string]todo)
+	for uuid := range m.pending {
+		if i := m.todoIndex(uuid); i >= 0 {
+			local[uuid] = m.todos[i]
+		}
+	}
+
+	// Keep the cursor on the same task rather than the same row
+	selected, hadSelection := m.selectedTodo()
+
+	m.loading = false
+	m.todos = msg.todos
+
+	for uuid, op := range m.pending {
+		i := m.todoIndex(uuid)
+		if op.kind == opDelete {
+			if i >= 0 {
+				m.todos = append(m.todos[:i], m.todos[i+1:]...)
+			}
+			continue
+		}
+
+		t, ok := local[uuid]
+		switch {
+		case !ok:
+		case i >= 0:
+			m.todos[i] = t
+		default:
+			m.todos = append(m.todos, t)
+		}
+	}
+
+	m.refresh()
+	if hadSelection {
+		m.selectTodo(selected.uuid)
+	}
+	m.loadErr = msg.err
+	if msg.err != nil {
+		return tea.Batch(m.notifyError(msg.err), m.startActiveTick())
+	}
+	return m.startActiveTick()
+}
+
+// startSpinner begins animating pending rows unless it is already running.
+func (m *App) startSpinner() tea.Cmd {
+	if m.spinning {
+		return nil
+	}
+	m.spinning = true
+	return m.spinner.Tick
+}
+
+func (m *App) handleSpinnerTick(msg spinner.TickMsg) tea.Cmd {
+	if len(m.pending) == 0 && !m.loading {
+		m.spinning = false
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.spinner, cmd = m.spinner.Update(msg)
+	m.updateTable()
+	return cmd
+}
+
+func (m *App) handleTodosSaved(msg todosSavedMsg) tea.Cmd {
+	// Stopping, completing or deleting an active task records the time spent
+	// on it, unless it is an undo or redo putting a task back
+	now := time.Now()
+	var changes []change
+	var worked []timelog.Entry
+	for _, t := range msg.todos {
+		op, ok := m.pending[t.uuid]
+		delete(m.pending, t.uuid)
+
+		if _, failed := msg.failed[t.uuid]; failed {
+			if ok {
+				m.revert(t.uuid, op)
+			}
+			continue
+		}
+
+		if i := m.todoIndex(t.uuid); i >= 0 {
+			m.todos[i] = t
+		} else {
+			m.todos = append(m.todos, t)
+		}
+
+		c := change{after: taskFromTodo(&t)}
+		if ok && op.kind == opUpdate {
+			c.before = taskFromTodo(&op.prev)
+			if entry, stopped := stoppedEntry(op.prev, &t, now); stopped && msg.opts.origin == originUser {
+				worked = append(worked, entry)
+			}
+		}
+		changes = append(changes, c)
+	}
+
+	m.record(changes, msg.opts)
+	m.refresh()
+	report := m.reportBatch("save", len(msg.todos), msg.failed, msg.opts.verb)
+	logged := m.logTime(worked)
+	return tea.Batch(report, logged, m.reload(), m.quitIfSaved(len(msg.failed) > 0))
+}
+
+func (m *App) handleTodosDeleted(msg todosDeletedMsg) tea.Cmd {
+	now := time.Now()
+	var changes []change
+	var worked []timelog.Entry
+	for _, t := range msg.todos {
+		op, ok := m.pending[t.uuid]
+		delete(m.pending, t.uuid)
+
+		if _, failed := msg.failed[t.uuid]; failed {
+			if ok {
+				m.revert(t.uuid, op)
+			}
+			continue
+		}
+
+		before := t
+		if ok {
+			before = op.prev
+		}
+		changes = append(changes, change{before: taskFromTodo(&before)})
+		if entry, stopped := stoppedEntry(before, nil, now); stopped && msg.opts.origin == originUser {
+			worked = append(worked, entry)
+		}
+	}
+
+	m.record(changes, msg.opts)
+	m.refresh()
+	report := m.reportBatch("delete", len(msg.todos), msg.failed, msg.opts.verb)
+	logged := m.logTime(worked)
+	return tea.Batch(report, logged, m.reload(), m.quitIfSaved(len(msg.failed) > 0))
+}
+
+// writing reports whether a task or time log write is still in flight.
+func (m *App) writing() bool {
+	return len(m.pending) > 0 || m.timeWrites > 0
+}
+
+// quit exits once the writes in flight have landed, so a change made just
+// before quitting is not lost when the program stops.
+func (m *App) quit() tea.Cmd {
+	if !m.writing() {
+		return tea.Quit
+	}
+	m.quitting = true
+	return m.startSpinner()
+}
+
+// handleQuittingKey lets a quit that is waiting on writes be forced or
+// called off.
+func (m *App) handleQuittingKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc":
+		m.quitting = false
+	}
+	return nil
+}
+
+// quitIfSaved finishes a waiting quit once the last write lands. A failed
+// write calls the quit off so its error can be read.
+func (m *App) quitIfSaved(failed bool) tea.Cmd {
+	if !m.quitting {
+		return nil
+	}
+	if failed {
+		m.quitting = false
+		return nil
+	}
+	if m.writing() {
+		return nil
+	}
+	return tea.Quit
+}
+
+// reportBatch reports the outcome of a write: the first error for a plain
+// write, or a success/failure summary for a bulk action.
+func (m *App) reportBatch(action string, total int, failed map[string]error, verb string) tea.Cmd {
+	var firstErr error
+	for _, err := range failed {
+		firstErr = err
+		break
+	}
+
+	if verb == "" {
+		if firstErr != nil {
+			return m.notifyError(fmt.Errorf("could not %s task: %w", action, firstErr))
+		}
+		return nil
+	}
+
+	switch {
+	case len(failed) == total:
+		return m.notifyError(fmt.Errorf("could not %s %s: %w", action, pluralTasks(total), firstErr))
+	case firstErr != nil:
+		return m.notify(severityWarning, fmt.Sprintf("%s %d of %s, %d failed: %v", verb, total-len(failed), pluralTasks(total), len(failed), firstErr))
+	}
+	return m.notify(severitySuccess, fmt.Sprintf("%s %s", verb, pluralTasks(total)))
+}
+
+func pluralTasks(n int) string {
+	if n == 1 {
+		return "1 task"
+	}
+	return fmt.Sprintf("%d tasks", n)
+}
+
+func (m *App) refresh() {
+	m.updateProjects()
+	m.updateTable()
+}
+
+func (m *App) todoIndex(uuid string) int {
+	if uuid == "" {
+		return -1
+	}
+	for i := range m.todos {
+		if m.todos[i].uuid == uuid {
+			return i
+		}
+	}
+	return -1
+}
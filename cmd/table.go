@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// priorityColumn is the index of the Pri column, which keeps its own colour.
+const priorityColumn = 1
+
+// dimColor is the colour of a row that cannot be started yet.
+var dimColor = lipgloss.Color("#6c7086")
+
+// rowColors is how a table row is coloured: the row as a whole, and the
+// priority cell on its own. An empty colour leaves the cell style's.
+type rowColors struct {
+	row      lipgloss.Color
+	priority lipgloss.Color
+}
+
+func tableStyles() table.Styles {
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("#6c7086")).
+		BorderBottom(true).
+		Bold(true).
+		Foreground(lipgloss.Color("#fab387")).
+		PaddingLeft(1).
+		PaddingRight(1)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("#1e1e2e")).
+		Background(lipgloss.Color("#f38ba8")).
+		Bold(true)
+	s.Cell = s.Cell.
+		Foreground(lipgloss.Color("#cdd6f4")).
+		PaddingLeft(1).
+		PaddingRight(1)
+	return s
+}
+
+// renderTable draws the header and the rows in view. The table's own view
+// truncates cells counting colour codes as text, which cuts true colour
+// codes short, so rows are drawn here and coloured after truncation.
+func (m *App) renderTable() string {
+	styles := tableStyles()
+	columns := m.table.Columns()
+
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = styles.Header.Render(fitCell(col.Title, col.Width))
+	}
+
+	rows := m.table.Rows()
+	var lines []string
+	for i := m.tableOffset; i < len(rows) && i < m.tableOffset+m.table.Height(); i++ {
+		lines = append(lines, m.renderRow(i, styles))
+	}
+
+	body := lipgloss.NewStyle().Height(m.table.Height()).Render(strings.Join(lines, "\n"))
+	return lipgloss.JoinHorizontal(lipgloss.Top, headers...) + "\n" + body
+}
+
+func (m *App) renderRow(i int, styles table.Styles) string {
+	row := m.table.Rows()[i]
+	var colors rowColors
+	if i < len(m.rowColors) {
+		colors = m.rowColors[i]
+	}
+
+	cells := make([]string, len(m.table.Columns()))
+	for j, col := range m.table.Columns() {
+		value := ""
+		if j < len(row) {
+			value = row[j]
+		}
+		cell := fitCell(value, col.Width)
+
+		if i == m.table.Cursor() {
+			// The highlight is applied to the whole row, so its cells are
+			// left uncoloured
+			cells[j] = lipgloss.NewStyle().Padding(0, 1).Render(cell)
+			continue
+		}
+		color := colors.row
+		if j == priorityColumn {
+			color = colors.priority
+		}
+		style := styles.Cell
+		if color != "" {
+			style = style.Foreground(color)
+		}
+		cells[j] = style.Render(cell)
+	}
+
+	line := lipgloss.JoinHorizontal(lipgloss.Top, cells...)
+	if i == m.table.Cursor() {
+		return styles.Selected.Render(line)
+	}
+	return line
+}
+
+// fitCell pads or truncates text to exactly width columns.
+func fitCell(text string, width int) string {
+	return lipgloss.NewStyle().Width(width).MaxWidth(width).Inline(true).Render(truncate(text, width))
+}
+
+// truncate shortens text to width columns, ending it with "…" when cut.
+func truncate(text string, width int) string {
+	if lipgloss.Width(text) <= width {
+		return text
+	}
+
+	var b strings.Builder
+	used := 0
+	for _, r := range text {
+		w := lipgloss.Width(string(r))
+		if used+w > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	return b.String() + "…"
+}
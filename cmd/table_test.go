@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestTruncate(t *testing.T) {
+	for _, tc := range []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"Buy milk", 10, "Buy milk"},
+		{"Buy milk", 8, "Buy milk"},
+		{"Buy oat milk", 8, "Buy oat…"},
+		{"日本語のタスク", 7, "日本語…"},
+	} {
+		if got := truncate(tc.text, tc.width); got != tc.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tc.text, tc.width, got, tc.want)
+		}
+	}
+}
+
+func TestRenderTableScrollsToCursor(t *testing.T) {
+	h := newHarness(t)
+	for i := range 10 {
+		h.addTask(fmt.Sprintf("Task %d", i))
+	}
+	h.app.table.SetCursor(0)
+	h.app.updateTable()
+
+	height := h.app.table.Height()
+	for range 9 {
+		h.press("j")
+	}
+	lines := strings.Split(h.app.renderTable(), "\n")
+	// The header takes two lines with its border
+	if len(lines) != height+2 {
+		t.Fatalf("table is %d lines, want %d", len(lines), height+2)
+	}
+	rows := h.app.table.Rows()
+	if first := lines[2]; !strings.Contains(first, rows[len(rows)-height][2]) {
+		t.Errorf("first row shown is %q, want %q", first, rows[len(rows)-height][2])
+	}
+	if last := lines[len(lines)-1]; !strings.Contains(last, rows[len(rows)-1][2]) {
+		t.Errorf("last row shown is %q, want the cursor row %q", last, rows[len(rows)-1][2])
+	}
+
+	for range 9 {
+		h.press("k")
+	}
+	if h.app.tableOffset != 0 {
+		t.Errorf("offset %d with the cursor on the first row", h.app.tableOffset)
+	}
+}
+
+func TestRenderTableTruncatesCells(t *testing.T) {
+	h := newHarness(t)
+	h.addTask("A task with a description far too long for its column")
+
+	lines := strings.Split(h.app.renderTable(), "\n")
+	width := lipgloss.Width(lines[0])
+	if got := lipgloss.Width(lines[2]); got != width {
+		t.Errorf("row is %d columns wide, want the header's %d", got, width)
+	}
+	if !strings.Contains(lines[2], "…") {
+		t.Errorf("long task shown as %q, want it cut short with …", lines[2])
+	}
+}
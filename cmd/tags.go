@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// parseTagWord reports whether word is a +tag or -tag modifier, returning
+// the tag and whether it is being added.
+func parseTagWord(word string) (tag string, add bool, ok bool) {
+	if len(word) < 2 || (word[0] != '+' && word[0] != '-') {
+		return "", false, false
+	}
+	// Only letters may follow the sign, so "-5" or "+3d" stay in the text
+	if c := word[1]; !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_') {
+		return "", false, false
+	}
+	return word[1:], word[0] == '+', true
+}
+
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return "+" + strings.Join(tags, " +")
+}
+
+func getUniqueTags(todos []todo) []string {
+	tagMap := make(map[string]bool)
+	for _, todo := range todos {
+		for _, tag := range todo.tags {
+			tagMap[tag] = true
+		}
+	}
+
+	var tags []string
+	for tag := range tagMap {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// matchesTagFilter reports whether todo has every tag in the tag filter.
+func (m *App) matchesTagFilter(todo todo) bool {
+	for _, tag := range m.tagFilter {
+		if !containsString(todo.tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *App) startTagSelection() {
+	m.tagSelectionMode = true
+	m.tagOptions = getUniqueTags(m.todos)
+	m.tagCursor = 0
+	m.tagChoices = make(map[string]bool)
+	for _, tag := range m.tagFilter {
+		m.tagChoices[tag] = true
+	}
+}
+
+func (m *App) handleTagSelectionKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		if m.tagCursor > 0 {
+			m.tagCursor--
+		}
+	case "down", "j":
+		if m.tagCursor < len(m.tagOptions)-1 {
+			m.tagCursor++
+		}
+	case " ":
+		if m.tagCursor < len(m.tagOptions) {
+			tag := m.tagOptions[m.tagCursor]
+			m.tagChoices[tag] = !m.tagChoices[tag]
+		}
+	case "c":
+		m.tagChoices = make(map[string]bool)
+	case "enter":
+		m.tagFilter = nil
+		for _, tag := range m.tagOptions {
+			if m.tagChoices[tag] {
+				m.tagFilter = append(m.tagFilter, tag)
+			}
+		}
+		m.tagSelectionMode = false
+		m.updateTable()
+	case "esc":
+		m.tagSelectionMode = false
+	case "ctrl+c", "q":
+		return m.quit()
+	}
+	return nil
+}
+
+func (m *App) renderTagSelection() string {
+	var items []string
+	for i, tag := range m.tagOptions {
+		cursor := "  "
+		if i == m.tagCursor {
+			cursor = "❯ "
+		}
+
+		selected := " "
+		if m.tagChoices[tag] {
+			selected = "✓"
+		}
+
+		line := fmt.Sprintf("%s[%s] +%s", cursor, selected, tag)
+		if i == m.tagCursor {
+			line = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#1e1e2e")).
+				Background(lipgloss.Color("#f38ba8")).
+				Bold(true).
+				Render(line)
+		}
+
+		items = append(items, line)
+	}
+
+	content := strings.Join(items, "\n")
+	if len(items) == 0 {
+		content = "No tags yet"
+	}
+
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#fab387")).
+		Bold(true).
+		Render("Select Tag Filter:")
+
+	instructions := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086")).
+		Render("Press space to toggle, c to clear, enter to apply, esc to cancel")
+
+	style := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#6c7086")).
+		Padding(1, 2).
+		Width(35)
+
+	return style.Render(title + "\n\n" + content + "\n\n" + instructions)
+}
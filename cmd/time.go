@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/EwanGreer/todolist/taskwarrior"
+	"github.com/EwanGreer/todolist/timelog"
+)
+
+var (
+	timeFrom string
+	timeTo   string
+)
+
+var timeCmd = &cobra.Command{
+	Use:   "time",
+	Short: "Summarise time tracked per task, project and day",
+	Long: `Summarise the time recorded by starting and stopping tasks, per task,
+per project and per day. Tasks still running count up to now.
+
+--from and --to accept the same dates as due:, such as 2026-10-01, today or
+yesterday. A --to date without a time includes the whole day.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		now := time.Now()
+		from, err := parseDue(timeFrom, now)
+		if err != nil {
+			return err
+		}
+		to, err := parseDue(timeTo, now)
+		if err != nil {
+			return err
+		}
+		end := time.Unix(to, 0)
+		if end.Equal(startOfDay(end)) {
+			end = end.AddDate(0, 0, 1)
+		}
+
+		var opts []taskwarrior.Option
+		if dataDir != "" {
+			opts = append(opts, taskwarrior.WithDataDir(dataDir))
+		}
+		tw, err := taskwarrior.New(opts...)
+		if err != nil {
+			return err
+		}
+
+		entries, err := timelog.New(tw.Location()).Entries()
+		if err != nil {
+			return err
+		}
+		// Tasks still running have no entry yet
+		pending, err := tw.LoadPendingTasks()
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Leaving out running tasks: %v\n", err)
+		}
+		for _, task := range pending {
+			if task.Start != 0 {
+				t := todoFromTask(task)
+				entries = append(entries, timeEntry(t, now))
+			}
+		}
+
+		summary := timelog.Summarize(entries, time.Unix(from, 0), end, time.Local)
+		printSummary(cmd.OutOrStdout(), summary, time.Unix(from, 0), end)
+		return nil
+	},
+}
+
+func printSummary(out io.Writer, summary timelog.Summary, from, to time.Time) {
+	fmt.Fprintf(out, "Time tracked %s to %s: %s\n", from.Format("2006-01-02 15:04"), to.Format("2006-01-02 15:04"), timelog.FormatDuration(summary.Total))
+	if summary.Total == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, section := range []struct {
+		title  string
+		totals []timelog.Total
+	}{
+		{"By project", summary.Projects},
+		{"By task", summary.Tasks},
+		{"By day", summary.Days},
+	} {
+		fmt.Fprintf(w, "\n%s\n", section.title)
+		for _, total := range section.totals {
+			fmt.Fprintf(w, "  %s\t%s\n", total.Name, timelog.FormatDuration(total.Duration))
+		}
+	}
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(timeCmd)
+	timeCmd.Flags().StringVar(&timeFrom, "from", time.Now().AddDate(0, 0, -6).Format("2006-01-02"), "Start of the range")
+	timeCmd.Flags().StringVar(&timeTo, "to", "today", "End of the range, inclusive")
+}
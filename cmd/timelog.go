@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/EwanGreer/todolist/timelog"
+)
+
+// maxTimeRows bounds how many totals each section of the time view lists.
+const maxTimeRows = 8
+
+// WithTimeLog records the time spent on tasks between starting and
+// stopping them in log.
+func WithTimeLog(log *timelog.Log) AppOption {
+	return func(m *App) {
+		m.timeLog = log
+	}
+}
+
+type timeLoggedMsg struct {
+	err error
+}
+
+type timeLogLoadedMsg struct {
+	entries []timelog.Entry
+	err     error
+}
+
+// timeEntry is the interval t has been running for, up to end.
+func timeEntry(t todo, end time.Time) timelog.Entry {
+	project := t.project
+	if project == "default" {
+		project = ""
+	}
+	return timelog.Entry{
+		UUID:        t.uuid,
+		Description: t.text,
+		Project:     project,
+		Start:       time.Unix(t.start, 0),
+		End:         end,
+	}
+}
+
+// stoppedEntry returns the interval worked on before, if the write that
+// turned it into after stopped it. after is nil for a delete.
+func stoppedEntry(before todo, after *todo, end time.Time) (timelog.Entry, bool) {
+	if before.start == 0 || before.completed || (after != nil && after.start == before.start) {
+		return timelog.Entry{}, false
+	}
+	return timeEntry(before, end), true
+}
+
+// logTime appends entries to the time log in the background.
+func (m *App) logTime(entries []timelog.Entry) tea.Cmd {
+	if m.timeLog == nil || len(entries) == 0 {
+		return nil
+	}
+
+	m.timeWrites++
+	log := m.timeLog
+	return func() tea.Msg {
+		return timeLoggedMsg{err: log.Append(entries...)}
+	}
+}
+
+func (m *App) handleTimeLogged(msg timeLoggedMsg) tea.Cmd {
+	m.timeWrites--
+	if msg.err != nil {
+		return tea.Batch(m.notifyError(fmt.Errorf("could not record time: %w", msg.err)), m.quitIfSaved(true))
+	}
+	return m.quitIfSaved(false)
+}
+
+type timeRange int
+
+const (
+	rangeToday timeRange = iota
+	rangeWeek
+	rangeMonth
+	rangeAll
+)
+
+func (r timeRange) String() string {
+	switch r {
+	case rangeWeek:
+		return "last 7 days"
+	case rangeMonth:
+		return "last 30 days"
+	case rangeAll:
+		return "all time"
+	}
+	return "today"
+}
+
+func (r timeRange) next() timeRange {
+	return (r + 1) % (rangeAll + 1)
+}
+
+// start is where the range begins; every range ends now.
+func (r timeRange) start(now time.Time) time.Time {
+	switch r {
+	case rangeWeek:
+		return startOfDay(now).AddDate(0, 0, -6)
+	case rangeMonth:
+		return startOfDay(now).AddDate(0, 0, -29)
+	case rangeAll:
+		return time.Time{}
+	}
+	return startOfDay(now)
+}
+
+func (m *App) openTimeView() tea.Cmd {
+	if m.timeLog == nil {
+		return m.notify(severityInfo, "Time tracking is not available")
+	}
+
+	m.timeMode = true
+	m.timeEntries = nil
+	log := m.timeLog
+	return func() tea.Msg {
+		entries, err := log.Entries()
+		return timeLogLoadedMsg{entries: entries, err: err}
+	}
+}
+
+func (m *App) handleTimeLogLoaded(msg timeLogLoadedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.timeMode = false
+		return m.notifyError(msg.err)
+	}
+	m.timeEntries = msg.entries
+	return nil
+}
+
+func (m *App) handleTimeViewKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "r", "tab":
+		m.timeRange = m.timeRange.next()
+	case "esc", "w", "q":
+		m.timeMode = false
+	case "ctrl+c":
+		return m.quit()
+	}
+	return nil
+}
+
+func (m *App) renderTimeView() string {
+	now := time.Now()
+	entries := m.timeEntries
+	// Tasks still running count up to now
+	for _, t := range m.activeTodos() {
+		entries = append(entries, timeEntry(t, now))
+	}
+	summary := timelog.Summarize(entries, m.timeRange.start(now), now, time.Local)
+
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#fab387")).
+		Bold(true).
+		Render(fmt.Sprintf("Time Tracked: %s (%s)", m.timeRange, timelog.FormatDuration(summary.Total)))
+
+	sectionStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#fab387")).
+		Bold(true)
+	nameStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#cdd6f4")).
+		Width(40)
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6c7086"))
+
+	content := title
+	if summary.Total == 0 {
+		content += "\n\n" + mutedStyle.Render("Nothing tracked yet: press s on a task to start it")
+	}
+	for _, section := range []struct {
+		title  string
+		totals []timelog.Total
+	}{
+		{"By Project", summary.Projects},
+		{"By Task", summary.Tasks},
+		{"By Day", summary.Days},
+	} {
+		if len(section.totals) == 0 {
+			continue
+		}
+
+		lines := []string{sectionStyle.Render(section.title)}
+		for i, total := range section.totals {
+			if i == maxTimeRows {
+				lines = append(lines, mutedStyle.Render(fmt.Sprintf("  … and %d more", len(section.totals)-i)))
+				break
+			}
+			name := []rune(total.Name)
+			if len(name) > 36 {
+				name = append(name[:35], '…')
+			}
+			lines = append(lines, nameStyle.Render("  "+string(name))+timelog.FormatDuration(total.Duration))
+		}
+		content += "\n\n" + strings.Join(lines, "\n")
+	}
+
+	instructions := mutedStyle.Render("r: change range • esc: close")
+
+	style := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#6c7086")).
+		Padding(1, 2).
+		Width(60)
+
+	return style.Render(content + "\n\n" + instructions)
+}
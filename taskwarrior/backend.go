@@ -0,0 +1,24 @@
+package taskwarrior
+
+import "context"
+
+// Backend is a task store the TUI can load tasks from and write tasks to.
+// Every call takes a context so a stuck store can be abandoned.
+type Backend interface {
+	LoadPendingTasksContext(ctx context.Context) ([]*Task, error)
+	LoadCompletedTasksContext(ctx context.Context) ([]*Task, error)
+	// LoadRecurringTasksContext loads the templates recurring tasks are
+	// generated from.
+	LoadRecurringTasksContext(ctx context.Context) ([]*Task, error)
+	QueryContext(ctx context.Context, filter string) ([]*Task, error)
+	SaveTaskContext(ctx context.Context, task *Task) error
+	DeleteTaskContext(ctx context.Context, uuid string) error
+	// SaveTasksContext and DeleteTasksContext write a whole batch in one
+	// round trip. Partial failures are reported as a *BatchError.
+	SaveTasksContext(ctx context.Context, tasks []*Task) error
+	DeleteTasksContext(ctx context.Context, uuids []string) error
+	// Location describes where the tasks are stored, for display.
+	Location() string
+}
+
+var _ Backend = (*TaskWarrior)(nil)
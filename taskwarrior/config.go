@@ -0,0 +1,100 @@
+package taskwarrior
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const maxIncludeDepth = 8
+
+// ResolveDataDir finds the data location the way Taskwarrior does: TASKDATA
+// wins, then data.location from the rc file named by TASKRC (or ~/.taskrc,
+// falling back to the XDG config location), then ~/.task.
+func ResolveDataDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	if dir := os.Getenv("TASKDATA"); dir != "" {
+		return expandHome(dir, homeDir), nil
+	}
+
+	if location := readDataLocation(rcPath(homeDir), homeDir, 0); location != "" {
+		return expandHome(location, homeDir), nil
+	}
+
+	return filepath.Join(homeDir, ".task"), nil
+}
+
+func rcPath(homeDir string) string {
+	if rc := os.Getenv("TASKRC"); rc != "" {
+		return expandHome(rc, homeDir)
+	}
+
+	rc := filepath.Join(homeDir, ".taskrc")
+	if _, err := os.Stat(rc); err == nil {
+		return rc
+	}
+
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configHome, "task", "taskrc")
+}
+
+// readDataLocation returns the last data.location set in the rc file at
+// path or any file it includes. Missing files are ignored, as Taskwarrior
+// resolves some includes (e.g. themes) from system directories.
+func readDataLocation(path, homeDir string, depth int) string {
+	if depth > maxIncludeDepth {
+		return ""
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	location := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if comment := strings.IndexByte(line, '#'); comment >= 0 {
+			line = line[:comment]
+		}
+		line = strings.TrimSpace(line)
+
+		if include, ok := strings.CutPrefix(line, "include "); ok {
+			include = expandHome(strings.TrimSpace(include), homeDir)
+			if !filepath.IsAbs(include) {
+				include = filepath.Join(filepath.Dir(path), include)
+			}
+			if included := readDataLocation(include, homeDir, depth+1); included != "" {
+				location = included
+			}
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(name) == "data.location" {
+			location = strings.TrimSpace(value)
+		}
+	}
+
+	return location
+}
+
+func expandHome(path, homeDir string) string {
+	if path == "~" {
+		return homeDir
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return filepath.Join(homeDir, rest)
+	}
+	return path
+}
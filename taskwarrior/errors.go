@@ -0,0 +1,81 @@
+package taskwarrior
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrBinaryNotFound = errors.New("taskwarrior: task binary not found in PATH")
+
+// ExitError reports a `task` invocation that exited with a non-zero status.
+type ExitError struct {
+	Args   []string
+	Code   int
+	Stderr string
+}
+
+func (e *ExitError) Error() string {
+	msg := fmt.Sprintf("taskwarrior: task %s exited with status %d", strings.Join(e.Args, " "), e.Code)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		msg += ": " + stderr
+	}
+	return msg
+}
+
+// DecodeError reports task data that could not be decoded.
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return "taskwarrior: decoding tasks: " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// TimeoutError reports a `task` invocation abandoned because its context was
+// cancelled or timed out, e.g. while waiting on a lock or a hook prompt.
+type TimeoutError struct {
+	Args []string
+	Err  error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("taskwarrior: task %s did not finish: %v", strings.Join(e.Args, " "), e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError reports the tasks of a batch write that were not written,
+// keyed by UUID.
+type BatchError struct {
+	Failed map[string]error
+}
+
+func newBatchError(err error, uuids []string) *BatchError {
+	failed := make(map[string]error, len(uuids))
+	for _, uuid := range uuids {
+		failed[uuid] = err
+	}
+	return &BatchError{Failed: failed}
+}
+
+func (e *BatchError) Error() string {
+	for _, err := range e.Failed {
+		return fmt.Sprintf("taskwarrior: %d tasks not written: %v", len(e.Failed), err)
+	}
+	return "taskwarrior: batch failed"
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, err := range e.Failed {
+		errs = append(errs, err)
+	}
+	return errs
+}
@@ -0,0 +1,305 @@
+package taskwarrior
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf16"
+)
+
+var errUnrecognisedFormat = errors.New("unrecognised data file format")
+
+// ff4Entities are the escapes Taskwarrior adds on top of JSON string
+// escaping, so brackets and quotes cannot end a line or a value early.
+var ff4Entities = strings.NewReplacer(
+	"&open;", "[",
+	"&close;", "]",
+	"&dquot;", `"`,
+)
+
+// loadTasksFromDataFiles reads tasks straight from the Taskwarrior 2.x
+// pending.data and completed.data files, skipping the `task` binary.
+// Pending tasks and recurring templates only ever live in pending.data, so
+// completed.data is read for completed tasks alone.
+func (tw *TaskWarrior) loadTasksFromDataFiles(ctx context.Context, status string) ([]*Task, error) {
+	pending, err := tw.readDataFile("pending.data", true)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	records := pending
+	if status == "completed" {
+		completed, err := tw.readDataFile("completed.data", false)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		records = append(records, completed...)
+	}
+
+	tasks := []*Task{}
+	for _, record := range records {
+		if record["status"] == status {
+			tasks = append(tasks, taskFromExport(record))
+		}
+	}
+	return tasks, nil
+}
+
+// readDataFile parses the data file name. It is read afresh every time:
+// Taskwarrior can rewrite it within the same second and at the same size,
+// so neither says whether it changed.
+func (tw *TaskWarrior) readDataFile(name string, assignIDs bool) ([]map[string]any, error) {
+	file, err := os.Open(filepath.Join(tw.dataDir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return readFF4(file, assignIDs)
+}
+
+func readFF4(r io.Reader, assignIDs bool) ([]map[string]any, error) {
+	var records []map[string]any
+	id := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		attrs, err := parseFF4Line(line)
+		if err != nil {
+			return nil, err
+		}
+
+		record := exportFromAttributes(attrs)
+		if assignIDs {
+			switch record["status"] {
+			case "pending", "waiting", "recurring":
+				id++
+				record["id"] = float64(id)
+			}
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// parseFF4Line decodes a single `[name:"value" ...]` line.
+func parseFF4Line(line string) (map[string]string, error) {
+	if len(line) < 2 || line[0] != '[' || line[len(line)-1] != ']' {
+		return nil, errUnrecognisedFormat
+	}
+	body := line[1 : len(line)-1]
+
+	attrs := make(map[string]string)
+	for i := 0; i < len(body); {
+		if body[i] == ' ' {
+			i++
+			continue
+		}
+
+		colon := strings.IndexByte(body[i:], ':')
+		if colon <= 0 {
+			return nil, errUnrecognisedFormat
+		}
+		name := body[i : i+colon]
+		i += colon + 1
+
+		if i >= len(body) || body[i] != '"' {
+			return nil, errUnrecognisedFormat
+		}
+		i++
+
+		start := i
+		for i < len(body) && body[i] != '"' {
+			if body[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(body) {
+			return nil, errUnrecognisedFormat
+		}
+		attrs[name] = unescapeFF4(body[start:i])
+		i++
+	}
+
+	if attrs["uuid"] == "" {
+		return nil, errUnrecognisedFormat
+	}
+	return foldTagsAndDepends(attrs), nil
+}
+
+// unescapeFF4 decodes a value as Taskwarrior does: entities first, then
+// JSON string escapes, including \uXXXX and surrogate pairs.
+func unescapeFF4(value string) string {
+	value = ff4Entities.Replace(value)
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			r, n := decodeUnicodeEscape(value[i-1:])
+			if n == 0 {
+				b.WriteString(`\u`)
+				continue
+			}
+			b.WriteRune(r)
+			i += n - 2
+		default:
+			// \" \\ \/ and anything unknown stand for the character itself
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// decodeUnicodeEscape decodes the \uXXXX escape, or surrogate pair of them,
+// at the start of s and returns the rune and how many bytes it took.
+func decodeUnicodeEscape(s string) (rune, int) {
+	r, ok := parseHex4(s)
+	if !ok {
+		return 0, 0
+	}
+	if utf16.IsSurrogate(r) {
+		if low, ok := parseHex4(s[6:]); ok {
+			if pair := utf16.DecodeRune(r, low); pair != unicode.ReplacementChar {
+				return pair, 12
+			}
+		}
+	}
+	return r, 6
+}
+
+func parseHex4(s string) (rune, bool) {
+	if len(s) < 6 || s[0] != '\\' || s[1] != 'u' {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s[2:6], 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(n), true
+}
+
+// foldTagsAndDepends merges the tag_<name> and dep_<uuid> keys written by
+// Taskwarrior 2.6+ and taskchampion into the comma-separated tags and
+// depends attributes, so every reader sees one shape.
+func foldTagsAndDepends(raw map[string]string) map[string]string {
+	attrs := make(map[string]string, len(raw))
+	var tags, depends []string
+
+	for name, value := range raw {
+		switch {
+		case strings.HasPrefix(name, "tag_"):
+			tags = append(tags, strings.TrimPrefix(name, "tag_"))
+		case strings.HasPrefix(name, "dep_"):
+			depends = append(depends, strings.TrimPrefix(name, "dep_"))
+		default:
+			attrs[name] = value
+		}
+	}
+
+	attrs["tags"] = mergeList(attrs["tags"], tags)
+	attrs["depends"] = mergeList(attrs["depends"], depends)
+	for _, name := range []string{"tags", "depends"} {
+		if attrs[name] == "" {
+			delete(attrs, name)
+		}
+	}
+	return attrs
+}
+
+// mergeList appends the items missing from list, in sorted order. list is
+// comma-separated, or a JSON array as some versions store depends.
+func mergeList(list string, items []string) string {
+	var merged []string
+	if strings.HasPrefix(list, "[") {
+		_ = json.Unmarshal([]byte(list), &merged)
+	} else if list != "" {
+		merged = strings.Split(list, ",")
+	}
+	sort.Strings(items)
+	for _, item := range items {
+		if !slices.Contains(merged, item) {
+			merged = append(merged, item)
+		}
+	}
+	return strings.Join(merged, ",")
+}
+
+// exportFromAttributes converts raw on-disk attributes into the shape
+// produced by `task export`, so both paths share taskFromExport.
+func exportFromAttributes(attrs map[string]string) map[string]any {
+	data := make(map[string]any, len(attrs))
+	var annotations []any
+
+	for name, value := range attrs {
+		switch {
+		case taskDates[name] != nil:
+			data[name] = formatEpoch(value)
+		case name == "id":
+			if id, err := strconv.ParseFloat(value, 64); err == nil {
+				data[name] = id
+			}
+		case strings.HasPrefix(name, "annotation_"):
+			annotations = append(annotations, map[string]any{
+				"entry":       formatEpoch(strings.TrimPrefix(name, "annotation_")),
+				"description": value,
+			})
+		default:
+			data[name] = value
+		}
+	}
+
+	if len(annotations) > 0 {
+		data["annotations"] = annotations
+	}
+	return data
+}
+
+func formatEpoch(value string) string {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return value
+	}
+	return time.Unix(seconds, 0).UTC().Format(timeFormat)
+}
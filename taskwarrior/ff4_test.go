@@ -0,0 +1,143 @@
+package taskwarrior
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testPendingData = `[description:"Write report" entry:"1760000000" status:"pending" tags:"work,urgent" uuid:"00000000-0000-4000-8000-000000000001"]
+[description:"Pay rent" due:"1760400000" entry:"1760000000" recur:"monthly" status:"recurring" uuid:"00000000-0000-4000-8000-000000000002"]
+[description:"Done, not yet moved" end:"1760100000" entry:"1760000000" status:"completed" uuid:"00000000-0000-4000-8000-000000000003"]
+`
+
+const testCompletedData = `[description:"Book flights" end:"1759950000" entry:"1759900000" status:"completed" uuid:"00000000-0000-4000-8000-000000000004"]
+`
+
+func writeDataFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadTasksFromDataFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeDataFile(t, dir, "pending.data", testPendingData)
+	writeDataFile(t, dir, "completed.data", testCompletedData)
+	tw, err := New(WithDataDir(dir))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for _, tc := range []struct {
+		status string
+		want   []string
+	}{
+		{"pending", []string{"Write report"}},
+		{"recurring", []string{"Pay rent"}},
+		{"completed", []string{"Done, not yet moved", "Book flights"}},
+	} {
+		tasks, err := tw.loadTasksFromDataFiles(t.Context(), tc.status)
+		if err != nil {
+			t.Fatalf("load %s: %v", tc.status, err)
+		}
+		var got []string
+		for _, task := range tasks {
+			got = append(got, task.Description)
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %q, want %q", tc.status, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: got %q, want %q", tc.status, got, tc.want)
+				break
+			}
+		}
+	}
+
+	pending, _ := tw.loadTasksFromDataFiles(t.Context(), "pending")
+	if task := pending[0]; task.ID != 1 || len(task.Tags) != 2 {
+		t.Errorf("pending task has ID %d and tags %v", task.ID, task.Tags)
+	}
+	recurring, _ := tw.loadTasksFromDataFiles(t.Context(), "recurring")
+	if task := recurring[0]; task.ID != 2 || task.Recur != "monthly" {
+		t.Errorf("recurring task has ID %d and recur %q", task.ID, task.Recur)
+	}
+}
+
+func TestLoadTasksFromDataFilesRereadsChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeDataFile(t, dir, "pending.data", testPendingData)
+	tw, err := New(WithDataDir(dir))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	path := filepath.Join(dir, "pending.data")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.loadTasksFromDataFiles(t.Context(), "pending"); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	// Same size and modification time, as a rewrite within one second of a
+	// coarse clock leaves them
+	writeDataFile(t, dir, "pending.data", strings.Replace(testPendingData, "Write report", "Write rep0rt", 1))
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := tw.loadTasksFromDataFiles(t.Context(), "pending")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Description != "Write rep0rt" {
+		t.Errorf("got %+v after the file changed, want the renamed task", tasks)
+	}
+}
+
+func TestUnescapeFF4(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{`plain`, "plain"},
+		{`&open;draft&close; &dquot;v2&dquot;`, `[draft] "v2"`},
+		{`line\nbreak\ttab`, "line\nbreak\ttab"},
+		{`a\/b \"q\" back\\slash`, `a/b "q" back\slash`},
+		{`caf\u00e9`, "café"},
+		{`\ud83d\ude80 launch`, "🚀 launch"},
+		{`\\u00e9`, `\u00e9`},
+		{`bad \u12`, `bad \u12`},
+	} {
+		if got := unescapeFF4(tc.in); got != tc.want {
+			t.Errorf("unescapeFF4(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseFF4LineFoldsTagsAndDepends(t *testing.T) {
+	line := `[dep_00000000-0000-4000-8000-000000000009:"x" depends:"00000000-0000-4000-8000-000000000008" description:"Ship" status:"pending" tag_release:"x" tags:"work" uuid:"00000000-0000-4000-8000-000000000001"]`
+	attrs, err := parseFF4Line(line)
+	if err != nil {
+		t.Fatalf("parseFF4Line: %v", err)
+	}
+	task := taskFromExport(exportFromAttributes(attrs))
+
+	if want := []string{"work", "release"}; !slices.Equal(task.Tags, want) {
+		t.Errorf("tags = %q, want %q", task.Tags, want)
+	}
+	want := []string{"00000000-0000-4000-8000-000000000008", "00000000-0000-4000-8000-000000000009"}
+	if !slices.Equal(task.Depends, want) {
+		t.Errorf("depends = %q, want %q", task.Depends, want)
+	}
+	if _, ok := task.UDA["tag_release"]; ok {
+		t.Error("tag_release kept as a UDA")
+	}
+}
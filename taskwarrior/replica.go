@@ -0,0 +1,193 @@
+package taskwarrior
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+const replicaFile = "taskchampion.sqlite3"
+
+var (
+	ErrReadOnly          = errors.New("taskwarrior: replica is read-only")
+	ErrUnsupportedFilter = errors.New("taskwarrior: unsupported filter")
+)
+
+// Replica reads tasks from a Taskwarrior 3 taskchampion database without
+// going through the `task` binary. It cannot write.
+type Replica struct {
+	path string
+}
+
+var _ Backend = (*Replica)(nil)
+
+func OpenReplica(dataDir string) (*Replica, error) {
+	path := filepath.Join(dataDir, replicaFile)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	return &Replica{path: path}, nil
+}
+
+func (r *Replica) Location() string {
+	return r.path
+}
+
+func (r *Replica) LoadPendingTasks() ([]*Task, error) {
+	return r.LoadPendingTasksContext(context.Background())
+}
+
+func (r *Replica) LoadPendingTasksContext(ctx context.Context) ([]*Task, error) {
+	return r.QueryContext(ctx, "status:pending")
+}
+
+func (r *Replica) LoadCompletedTasks() ([]*Task, error) {
+	return r.LoadCompletedTasksContext(context.Background())
+}
+
+func (r *Replica) LoadCompletedTasksContext(ctx context.Context) ([]*Task, error) {
+	return r.QueryContext(ctx, "status:completed")
+}
+
+func (r *Replica) LoadRecurringTasks() ([]*Task, error) {
+	return r.LoadRecurringTasksContext(context.Background())
+}
+
+func (r *Replica) LoadRecurringTasksContext(ctx context.Context) ([]*Task, error) {
+	return r.QueryContext(ctx, "status:recurring")
+}
+
+func (r *Replica) Query(filter string) ([]*Task, error) {
+	return r.QueryContext(context.Background(), filter)
+}
+
+// QueryContext supports filters made of space-separated name:value terms,
+// which must all match exactly.
+func (r *Replica) QueryContext(ctx context.Context, filter string) ([]*Task, error) {
+	terms := make(map[string]string)
+	for term := range strings.FieldsSeq(filter) {
+		name, value, ok := strings.Cut(term, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedFilter, term)
+		}
+		terms[name] = value
+	}
+
+	records, err := r.readRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []*Task{}
+	for _, attrs := range records {
+		if matchesTerms(attrs, terms) {
+			tasks = append(tasks, taskFromExport(exportFromAttributes(attrs)))
+		}
+	}
+	return tasks, nil
+}
+
+func (r *Replica) SaveTask(task *Task) error {
+	return ErrReadOnly
+}
+
+func (r *Replica) SaveTaskContext(ctx context.Context, task *Task) error {
+	return ErrReadOnly
+}
+
+func (r *Replica) SaveTasksContext(ctx context.Context, tasks []*Task) error {
+	return ErrReadOnly
+}
+
+func (r *Replica) DeleteTask(uuid string) error {
+	return ErrReadOnly
+}
+
+func (r *Replica) DeleteTaskContext(ctx context.Context, uuid string) error {
+	return ErrReadOnly
+}
+
+func (r *Replica) DeleteTasksContext(ctx context.Context, uuids []string) error {
+	return ErrReadOnly
+}
+
+func (r *Replica) readRecords(ctx context.Context) ([]map[string]string, error) {
+	// An opaque file: URI, so a relative path is not read as a host
+	dsn := "file:" + (&url.URL{Path: r.path}).EscapedPath() + "?mode=ro&_pragma=busy_timeout(5000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ids, err := readWorkingSet(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT uuid, data FROM tasks")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []map[string]string
+	for rows.Next() {
+		var uuid, data string
+		if err := rows.Scan(&uuid, &data); err != nil {
+			return nil, err
+		}
+
+		var raw map[string]string
+		if err := json.Unmarshal([]byte(data), &raw); err != nil {
+			return nil, fmt.Errorf("task %s: %w", uuid, err)
+		}
+
+		attrs := foldTagsAndDepends(raw)
+		attrs["uuid"] = uuid
+		if id, ok := ids[uuid]; ok {
+			attrs["id"] = id
+		}
+		records = append(records, attrs)
+	}
+	return records, rows.Err()
+}
+
+func readWorkingSet(ctx context.Context, db *sql.DB) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT id, uuid FROM working_set WHERE uuid IS NOT NULL")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]string)
+	for rows.Next() {
+		var id, uuid string
+		if err := rows.Scan(&id, &uuid); err != nil {
+			return nil, err
+		}
+		ids[uuid] = id
+	}
+	return ids, rows.Err()
+}
+
+func matchesTerms(attrs map[string]string, terms map[string]string) bool {
+	for name, value := range terms {
+		if attrs[name] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package taskwarrior
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+const (
+	releaseNotesUUID = "5a4c6bc4-8f5e-4d1b-9c3a-2f1e0d9c8b7a"
+	reviewPRUUID     = "7b8d9e0f-1a2b-4c3d-8e4f-5a6b7c8d9e0f"
+	bookFlightsUUID  = "9c0d1e2f-3a4b-4c5d-9e6f-7a8b9c0d1e2f"
+)
+
+func openTestReplica(t *testing.T) *Replica {
+	t.Helper()
+	replica, err := OpenReplica("testdata")
+	if err != nil {
+		t.Fatalf("OpenReplica: %v", err)
+	}
+	return replica
+}
+
+func TestReplicaCounts(t *testing.T) {
+	replica := openTestReplica(t)
+
+	for _, tc := range []struct {
+		name string
+		load func() ([]*Task, error)
+		want int
+	}{
+		{"pending", replica.LoadPendingTasks, 2},
+		{"completed", replica.LoadCompletedTasks, 1},
+		{"recurring", replica.LoadRecurringTasks, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tasks, err := tc.load()
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if len(tasks) != tc.want {
+				t.Errorf("got %d tasks, want %d", len(tasks), tc.want)
+			}
+			for _, task := range tasks {
+				if task.Status != tc.name {
+					t.Errorf("task %s has status %q", task.UUID, task.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestReplicaPendingTasks(t *testing.T) {
+	tasks, err := openTestReplica(t).LoadPendingTasks()
+	if err != nil {
+		t.Fatalf("LoadPendingTasks: %v", err)
+	}
+	byUUID := make(map[string]*Task)
+	for _, task := range tasks {
+		byUUID[task.UUID] = task
+	}
+
+	notes := byUUID[releaseNotesUUID]
+	if notes == nil {
+		t.Fatalf("task %s not loaded", releaseNotesUUID)
+	}
+	if notes.ID != 1 {
+		t.Errorf("ID = %d, want 1 from the working set", notes.ID)
+	}
+	if notes.Description != "Write release notes" || notes.Project != "work.docs" || notes.Priority != "H" {
+		t.Errorf("attributes = %q, %q, %q", notes.Description, notes.Project, notes.Priority)
+	}
+	if !slices.Equal(notes.Tags, []string{"release"}) {
+		t.Errorf("Tags = %v, want [release]", notes.Tags)
+	}
+	if len(notes.Annotations) != 1 || notes.Annotations[0].Description != "draft in shared drive" || notes.Annotations[0].Entry != 1760000100 {
+		t.Errorf("Annotations = %+v", notes.Annotations)
+	}
+
+	review := byUUID[reviewPRUUID]
+	if review == nil {
+		t.Fatalf("task %s not loaded", reviewPRUUID)
+	}
+	if review.ID != 2 {
+		t.Errorf("ID = %d, want 2 from the working set", review.ID)
+	}
+	if !slices.Equal(review.Depends, []string{releaseNotesUUID}) {
+		t.Errorf("Depends = %v, want [%s]", review.Depends, releaseNotesUUID)
+	}
+	if review.Due != 1760400000 {
+		t.Errorf("Due = %d, want 1760400000", review.Due)
+	}
+}
+
+func TestReplicaCompletedTasks(t *testing.T) {
+	tasks, err := openTestReplica(t).LoadCompletedTasks()
+	if err != nil {
+		t.Fatalf("LoadCompletedTasks: %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("got %d tasks, want 1", len(tasks))
+	}
+	if task := tasks[0]; task.UUID != bookFlightsUUID || task.ID != 0 || task.End != 1759950000 {
+		t.Errorf("task = %s, ID %d, End %d", task.UUID, task.ID, task.End)
+	}
+}
+
+func TestReplicaQuery(t *testing.T) {
+	replica := openTestReplica(t)
+
+	tasks, err := replica.Query("status:pending project:work.backend")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].UUID != reviewPRUUID {
+		t.Errorf("Query matched %d tasks, want only %s", len(tasks), reviewPRUUID)
+	}
+
+	if _, err := replica.Query("+release"); !errors.Is(err, ErrUnsupportedFilter) {
+		t.Errorf("Query(+release) error = %v, want ErrUnsupportedFilter", err)
+	}
+}
+
+func TestReplicaIsReadOnly(t *testing.T) {
+	replica := openTestReplica(t)
+	if err := replica.SaveTask(&Task{Description: "new"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("SaveTask error = %v, want ErrReadOnly", err)
+	}
+	if err := replica.DeleteTask(releaseNotesUUID); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("DeleteTask error = %v, want ErrReadOnly", err)
+	}
+}
+
+func TestOpenReplicaMissing(t *testing.T) {
+	if _, err := OpenReplica(t.TempDir()); err == nil {
+		t.Error("OpenReplica succeeded without a database")
+	}
+}
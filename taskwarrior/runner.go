@@ -0,0 +1,45 @@
+package taskwarrior
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"time"
+)
+
+// Runner executes a Taskwarrior command line and returns its standard
+// output. input, when non-nil, is fed to the command's standard input.
+// Implementations must give up once ctx is done.
+type Runner interface {
+	Run(ctx context.Context, input []byte, args ...string) ([]byte, error)
+}
+
+// ExecRunner runs the real `task` binary found in PATH.
+type ExecRunner struct{}
+
+func (ExecRunner) Run(ctx context.Context, input []byte, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "task", args...)
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, &TimeoutError{Args: args, Err: ctxErr}
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, ErrBinaryNotFound
+		}
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			return nil, &ExitError{Args: args, Code: exitError.ExitCode(), Stderr: stderr.String()}
+		}
+		return nil, err
+	}
+	return output, nil
+}
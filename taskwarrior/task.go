@@ -0,0 +1,218 @@
+package taskwarrior
+
+import (
+	"encoding/json"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+)
+
+const timeFormat = "20060102T150405Z"
+
+type Annotation struct {
+	Entry       int64
+	Description string
+}
+
+// Task mirrors a task as produced by `task export`. Attributes without a
+// dedicated field, such as UDAs and recurrence masks, are kept in UDA so
+// they survive a load/save round trip.
+type Task struct {
+	ID          int
+	UUID        string
+	Description string
+	Project     string
+	Status      string
+	Priority    string
+	Entry       int64
+	Modified    int64
+	End         int64
+	Start       int64
+	Due         int64
+	Scheduled   int64
+	Wait        int64
+	Until       int64
+	Tags        []string
+	Annotations []Annotation
+	Depends     []string
+	Recur       string
+	Parent      string
+	Urgency     float64
+	UDA         map[string]any
+}
+
+var taskStrings = map[string]func(*Task) *string{
+	"uuid":        func(t *Task) *string { return &t.UUID },
+	"description": func(t *Task) *string { return &t.Description },
+	"project":     func(t *Task) *string { return &t.Project },
+	"status":      func(t *Task) *string { return &t.Status },
+	"priority":    func(t *Task) *string { return &t.Priority },
+	"recur":       func(t *Task) *string { return &t.Recur },
+	"parent":      func(t *Task) *string { return &t.Parent },
+}
+
+var taskDates = map[string]func(*Task) *int64{
+	"entry":     func(t *Task) *int64 { return &t.Entry },
+	"modified":  func(t *Task) *int64 { return &t.Modified },
+	"end":       func(t *Task) *int64 { return &t.End },
+	"start":     func(t *Task) *int64 { return &t.Start },
+	"due":       func(t *Task) *int64 { return &t.Due },
+	"scheduled": func(t *Task) *int64 { return &t.Scheduled },
+	"wait":      func(t *Task) *int64 { return &t.Wait },
+	"until":     func(t *Task) *int64 { return &t.Until },
+}
+
+// Clone returns a deep copy of the task.
+func (t *Task) Clone() *Task {
+	clone := *t
+	clone.Tags = append([]string(nil), t.Tags...)
+	clone.Annotations = append([]Annotation(nil), t.Annotations...)
+	clone.Depends = append([]string(nil), t.Depends...)
+	clone.UDA = maps.Clone(t.UDA)
+	return &clone
+}
+
+func (t *Task) HasTag(tag string) bool {
+	for _, existing := range t.Tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Task) UnmarshalJSON(data []byte) error {
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*t = *taskFromExport(fields)
+	return nil
+}
+
+func (t *Task) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.toExport())
+}
+
+func taskFromExport(data map[string]any) *Task {
+	task := &Task{}
+
+	for name, value := range data {
+		if field, ok := taskStrings[name]; ok {
+			if str, ok := value.(string); ok {
+				*field(task) = str
+			}
+			continue
+		}
+		if field, ok := taskDates[name]; ok {
+			if str, ok := value.(string); ok {
+				if timestamp, err := time.Parse(timeFormat, str); err == nil {
+					*field(task) = timestamp.Unix()
+				}
+			}
+			continue
+		}
+
+		switch name {
+		case "id":
+			if id, ok := value.(float64); ok {
+				task.ID = int(id)
+			}
+		case "urgency":
+			if urgency, ok := value.(float64); ok {
+				task.Urgency = urgency
+			}
+		case "tags":
+			task.Tags = stringList(value)
+		case "depends":
+			task.Depends = stringList(value)
+		case "annotations":
+			items, _ := value.([]any)
+			for _, item := range items {
+				fields, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+				annotation := Annotation{}
+				if description, ok := fields["description"].(string); ok {
+					annotation.Description = description
+				}
+				if entry, ok := fields["entry"].(string); ok {
+					if timestamp, err := time.Parse(timeFormat, entry); err == nil {
+						annotation.Entry = timestamp.Unix()
+					}
+				}
+				task.Annotations = append(task.Annotations, annotation)
+			}
+			sort.SliceStable(task.Annotations, func(i, j int) bool {
+				return task.Annotations[i].Entry < task.Annotations[j].Entry
+			})
+		default:
+			if task.UDA == nil {
+				task.UDA = make(map[string]any)
+			}
+			task.UDA[name] = value
+		}
+	}
+
+	return task
+}
+
+// toExport is the inverse of taskFromExport. The computed id and urgency
+// attributes are left out because Taskwarrior never stores them.
+func (t *Task) toExport() map[string]any {
+	data := make(map[string]any, len(t.UDA)+len(taskStrings)+len(taskDates)+3)
+	maps.Copy(data, t.UDA)
+
+	for name, field := range taskStrings {
+		if value := *field(t); value != "" {
+			data[name] = value
+		}
+	}
+	for name, field := range taskDates {
+		if value := *field(t); value != 0 {
+			data[name] = time.Unix(value, 0).UTC().Format(timeFormat)
+		}
+	}
+
+	if len(t.Tags) > 0 {
+		data["tags"] = t.Tags
+	}
+	if len(t.Depends) > 0 {
+		data["depends"] = t.Depends
+	}
+	if len(t.Annotations) > 0 {
+		annotations := make([]map[string]string, len(t.Annotations))
+		for i, annotation := range t.Annotations {
+			annotations[i] = map[string]string{
+				"entry":       time.Unix(annotation.Entry, 0).UTC().Format(timeFormat),
+				"description": annotation.Description,
+			}
+		}
+		data["annotations"] = annotations
+	}
+
+	return data
+}
+
+// stringList accepts both the JSON array form and the older
+// comma-separated form Taskwarrior uses for list attributes.
+func stringList(value any) []string {
+	var items []string
+	switch value := value.(type) {
+	case []any:
+		for _, item := range value {
+			if str, ok := item.(string); ok && str != "" {
+				items = append(items, str)
+			}
+		}
+	case string:
+		for item := range strings.SplitSeq(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
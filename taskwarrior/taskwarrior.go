@@ -1,174 +1,291 @@
 package taskwarrior
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"regexp"
 	"time"
 )
 
-type Task struct {
-	UUID        string
-	Description string
-	Project     string
-	Status      string
-	Entry       int64
-	Modified    int64
-	End         int64
-}
+// DefaultTimeout bounds the methods that do not take a context.
+const DefaultTimeout = 30 * time.Second
 
 type TaskWarrior struct {
 	dataDir string
+	runner  Runner
+	timeout time.Duration
 }
 
-func New() (*TaskWarrior, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, err
+type Option func(*TaskWarrior)
+
+// WithDataDir uses dir as the Taskwarrior data location instead of the one
+// resolved from the environment and .taskrc.
+func WithDataDir(dir string) Option {
+	return func(tw *TaskWarrior) {
+		tw.dataDir = dir
+	}
+}
+
+// WithRunner replaces the `task` binary with r, e.g. a fake in tests.
+func WithRunner(r Runner) Option {
+	return func(tw *TaskWarrior) {
+		tw.runner = r
 	}
+}
 
-	dataDir := filepath.Join(homeDir, ".task")
+// WithTimeout changes how long the methods that do not take a context wait
+// for Taskwarrior before giving up.
+func WithTimeout(d time.Duration) Option {
+	return func(tw *TaskWarrior) {
+		tw.timeout = d
+	}
+}
 
-	if err := os.MkdirAll(dataDir, 0o755); err != nil {
-		return nil, err
+func New(opts ...Option) (*TaskWarrior, error) {
+	tw := &TaskWarrior{runner: ExecRunner{}, timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(tw)
 	}
 
-	return &TaskWarrior{dataDir: dataDir}, nil
+	if tw.dataDir == "" {
+		dataDir, err := ResolveDataDir()
+		if err != nil {
+			return nil, err
+		}
+		tw.dataDir = dataDir
+	}
+
+	return tw, nil
+}
+
+func (tw *TaskWarrior) Location() string {
+	return tw.dataDir
+}
+
+func (tw *TaskWarrior) withTimeout() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), tw.timeout)
 }
 
 func (tw *TaskWarrior) LoadPendingTasks() ([]*Task, error) {
-	return tw.loadTasksFromCommand("status:pending")
+	ctx, cancel := tw.withTimeout()
+	defer cancel()
+	return tw.LoadPendingTasksContext(ctx)
+}
+
+func (tw *TaskWarrior) LoadPendingTasksContext(ctx context.Context) ([]*Task, error) {
+	return tw.loadTasksByStatus(ctx, "pending")
 }
 
 func (tw *TaskWarrior) LoadCompletedTasks() ([]*Task, error) {
-	return tw.loadTasksFromCommand("status:completed")
+	ctx, cancel := tw.withTimeout()
+	defer cancel()
+	return tw.LoadCompletedTasksContext(ctx)
 }
 
-func (tw *TaskWarrior) loadTasksFromCommand(filter string) ([]*Task, error) {
-	cmd := exec.Command("task", "rc.data.location="+tw.dataDir, filter, "export")
-	output, err := cmd.Output()
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			if exitError.ExitCode() == 1 {
-				return []*Task{}, nil
-			}
-		}
-		return []*Task{}, nil
-	}
+func (tw *TaskWarrior) LoadCompletedTasksContext(ctx context.Context) ([]*Task, error) {
+	return tw.loadTasksByStatus(ctx, "completed")
+}
 
-	if len(output) == 0 || string(output) == "[]\n" || string(output) == "[]" {
-		return []*Task{}, nil
+func (tw *TaskWarrior) LoadRecurringTasks() ([]*Task, error) {
+	ctx, cancel := tw.withTimeout()
+	defer cancel()
+	return tw.LoadRecurringTasksContext(ctx)
+}
+
+func (tw *TaskWarrior) LoadRecurringTasksContext(ctx context.Context) ([]*Task, error) {
+	return tw.loadTasksByStatus(ctx, "recurring")
+}
+
+// loadTasksByStatus reads the taskchampion replica or the 2.x data files
+// directly, and runs `task export` only when neither is there to read. A
+// store that is there but cannot be read is an error rather than a reason
+// to try the next one, which would hide it.
+func (tw *TaskWarrior) loadTasksByStatus(ctx context.Context, status string) ([]*Task, error) {
+	replica, err := OpenReplica(tw.dataDir)
+	switch {
+	case err == nil:
+		return replica.QueryContext(ctx, "status:"+status)
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
 	}
 
-	var taskData []map[string]any
-	if err := json.Unmarshal(output, &taskData); err != nil {
-		return []*Task{}, nil
+	tasks, err := tw.loadTasksFromDataFiles(ctx, status)
+	if err == nil || !errors.Is(err, os.ErrNotExist) && !errors.Is(err, errUnrecognisedFormat) {
+		return tasks, err
 	}
+	return tw.loadTasksFromCommand(ctx, "status:"+status)
+}
 
-	var tasks []*Task
-	for _, data := range taskData {
-		task := &Task{}
+func (tw *TaskWarrior) Query(filter string) ([]*Task, error) {
+	ctx, cancel := tw.withTimeout()
+	defer cancel()
+	return tw.QueryContext(ctx, filter)
+}
 
-		if uuid, ok := data["uuid"].(string); ok {
-			task.UUID = uuid
-		}
-		if desc, ok := data["description"].(string); ok {
-			task.Description = desc
-		}
-		if project, ok := data["project"].(string); ok {
-			task.Project = project
-		}
-		if status, ok := data["status"].(string); ok {
-			task.Status = status
-		}
-		if entry, ok := data["entry"].(string); ok {
-			if timestamp, err := time.Parse("20060102T150405Z", entry); err == nil {
-				task.Entry = timestamp.Unix()
-			}
-		}
-		if modified, ok := data["modified"].(string); ok {
-			if timestamp, err := time.Parse("20060102T150405Z", modified); err == nil {
-				task.Modified = timestamp.Unix()
-			}
-		}
-		if end, ok := data["end"].(string); ok {
-			if timestamp, err := time.Parse("20060102T150405Z", end); err == nil {
-				task.End = timestamp.Unix()
-			}
-		}
+func (tw *TaskWarrior) QueryContext(ctx context.Context, filter string) ([]*Task, error) {
+	return tw.loadTasksFromCommand(ctx, filter)
+}
 
-		tasks = append(tasks, task)
+// runTask runs a `task` command against the configured data location with
+// confirmation prompts disabled.
+func (tw *TaskWarrior) runTask(ctx context.Context, input []byte, args ...string) ([]byte, error) {
+	args = append([]string{"rc.data.location=" + tw.dataDir, "rc.confirmation=off"}, args...)
+	return tw.runner.Run(ctx, input, args...)
+}
+
+func (tw *TaskWarrior) loadTasksFromCommand(ctx context.Context, filter string) ([]*Task, error) {
+	output, err := tw.runTask(ctx, nil, filter, "export")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bytes.TrimSpace(output)) == 0 {
+		return []*Task{}, nil
+	}
+
+	tasks := []*Task{}
+	if err := json.Unmarshal(output, &tasks); err != nil {
+		return nil, &DecodeError{Err: err}
 	}
 
 	return tasks, nil
 }
 
 func (tw *TaskWarrior) SaveTask(task *Task) error {
-	return tw.saveTaskWithCommand(task)
+	ctx, cancel := tw.withTimeout()
+	defer cancel()
+	return tw.SaveTaskContext(ctx, task)
 }
 
-func (tw *TaskWarrior) saveTaskWithCommand(task *Task) error {
-	var cmd *exec.Cmd
+func (tw *TaskWarrior) SaveTaskContext(ctx context.Context, task *Task) error {
+	return tw.saveTasksWithImport(ctx, []*Task{task})
+}
 
-	if task.UUID == "" {
-		args := []string{"rc.data.location=" + tw.dataDir, "rc.confirmation=off", "add"}
-		if task.Project != "" && task.Project != "default" {
-			args = append(args, "project:"+task.Project)
+// SaveTasksContext writes all tasks with a single `task import`. If that
+// fails, each task is imported on its own so that only the ones Taskwarrior
+// rejects are reported, as a *BatchError. Importing a task again is
+// harmless: it replaces the task with the same UUID.
+func (tw *TaskWarrior) SaveTasksContext(ctx context.Context, tasks []*Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	err := tw.saveTasksWithImport(ctx, tasks)
+	if err == nil {
+		return nil
+	}
+	if len(tasks) == 1 || ctx.Err() != nil {
+		return newBatchError(err, taskUUIDs(tasks))
+	}
+
+	failed := make(map[string]error)
+	for _, task := range tasks {
+		if err := tw.saveTasksWithImport(ctx, []*Task{task}); err != nil {
+			failed[task.UUID] = err
 		}
-		args = append(args, task.Description)
-		cmd = exec.Command("task", args...)
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &BatchError{Failed: failed}
+}
 
-		output, err := cmd.Output()
-		if err != nil {
+// saveTasksWithImport writes whole tasks through `task import`, so the
+// description and attributes are stored verbatim rather than parsed as
+// command-line arguments.
+func (tw *TaskWarrior) saveTasksWithImport(ctx context.Context, tasks []*Task) error {
+	for _, task := range tasks {
+		if err := prepareForImport(task); err != nil {
 			return err
 		}
+	}
 
-		outputStr := string(output)
-		re := regexp.MustCompile(`Created task (\S+)\.`)
-		matches := re.FindStringSubmatch(outputStr)
-		if len(matches) > 1 {
-			task.UUID = matches[1]
-		}
-	} else {
-		args := []string{"rc.data.location=" + tw.dataDir, "rc.confirmation=off", task.UUID, "modify"}
-		if task.Project != "" && task.Project != "default" {
-			args = append(args, "project:"+task.Project)
-		}
-		args = append(args, task.Description)
-		cmd = exec.Command("task", args...)
+	payload, err := json.Marshal(tasks)
+	if err != nil {
+		return err
+	}
 
-		if _, err := cmd.Output(); err != nil {
+	_, err = tw.runTask(ctx, payload, "import")
+	return err
+}
+
+// prepareForImport fills in the attributes Taskwarrior would set itself on
+// add, modify and done.
+func prepareForImport(task *Task) error {
+	now := time.Now().Unix()
+
+	if task.UUID == "" {
+		uuid, err := NewUUID()
+		if err != nil {
 			return err
 		}
+		task.UUID = uuid
+	}
+	if task.Entry == 0 {
+		task.Entry = now
+	}
+	if task.Status == "" {
+		task.Status = "pending"
 	}
 
 	switch task.Status {
-	case "completed":
-		cmd = exec.Command("task", "rc.data.location="+tw.dataDir, "rc.confirmation=off", task.UUID, "done")
-		if _, err := cmd.Output(); err != nil {
-			return err
-		}
-	case "pending":
-		currentTasks, err := tw.loadTasksFromCommand("uuid:" + task.UUID)
-		if err == nil && len(currentTasks) > 0 && currentTasks[0].Status == "completed" {
-			cmd = exec.Command("task", "rc.data.location="+tw.dataDir, "rc.confirmation=off", task.UUID, "modify", "status:pending")
-			if _, err := cmd.Output(); err != nil {
-				return err
-			}
+	case "completed", "deleted":
+		if task.End == 0 {
+			task.End = now
 		}
+	default:
+		task.End = 0
 	}
-
+	task.Modified = now
 	return nil
 }
 
-func (tw *TaskWarrior) deleteTaskWithCommand(uuid string) error {
-	cmd := exec.Command("task", "rc.data.location="+tw.dataDir, "rc.confirmation=off", uuid, "delete")
-	_, err := cmd.Output()
+// NewUUID returns a random (version 4) task UUID.
+func NewUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+func (tw *TaskWarrior) deleteTasksWithCommand(ctx context.Context, uuids []string) error {
+	args := append([]string{"rc.bulk=0"}, uuids...)
+	_, err := tw.runTask(ctx, nil, append(args, "delete")...)
 	return err
 }
 
 func (tw *TaskWarrior) DeleteTask(uuid string) error {
-	return tw.deleteTaskWithCommand(uuid)
+	ctx, cancel := tw.withTimeout()
+	defer cancel()
+	return tw.DeleteTaskContext(ctx, uuid)
+}
+
+func (tw *TaskWarrior) DeleteTaskContext(ctx context.Context, uuid string) error {
+	return tw.deleteTasksWithCommand(ctx, []string{uuid})
+}
+
+// DeleteTasksContext deletes all uuids with a single `task delete`. A
+// failure is reported as a *BatchError naming every task in the batch.
+func (tw *TaskWarrior) DeleteTasksContext(ctx context.Context, uuids []string) error {
+	if len(uuids) == 0 {
+		return nil
+	}
+	if err := tw.deleteTasksWithCommand(ctx, uuids); err != nil {
+		return newBatchError(err, uuids)
+	}
+	return nil
+}
+
+func taskUUIDs(tasks []*Task) []string {
+	uuids := make([]string, len(tasks))
+	for i, task := range tasks {
+		uuids[i] = task.UUID
+	}
+	return uuids
 }
@@ -0,0 +1,341 @@
+package taskwarrior_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/EwanGreer/todolist/taskwarrior"
+	"github.com/EwanGreer/todolist/taskwarrior/taskwarriortest"
+)
+
+// runnerFunc adapts a function to taskwarrior.Runner.
+type runnerFunc func(ctx context.Context, input []byte, args ...string) ([]byte, error)
+
+func (f runnerFunc) Run(ctx context.Context, input []byte, args ...string) ([]byte, error) {
+	return f(ctx, input, args...)
+}
+
+func newFake(t *testing.T) (*taskwarrior.TaskWarrior, *taskwarriortest.Taskwarrior) {
+	t.Helper()
+	tw, fake, err := taskwarriortest.NewTaskWarrior(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewTaskWarrior: %v", err)
+	}
+	return tw, fake
+}
+
+func TestSaveTaskRoundTrip(t *testing.T) {
+	tw, _ := newFake(t)
+
+	saved := &taskwarrior.Task{
+		Description: `Quote "this" [and that]`,
+		Project:     "work.api",
+		Priority:    "H",
+		Due:         time.Date(2026, 10, 20, 17, 0, 0, 0, time.UTC).Unix(),
+		Tags:        []string{"review", "urgent"},
+		Annotations: []taskwarrior.Annotation{{Entry: 1760000000, Description: "asked on chat"}},
+		Depends:     []string{"5a4c6bc4-8f5e-4d1b-9c3a-2f1e0d9c8b7a"},
+		UDA:         map[string]any{"estimate": "2h"},
+	}
+	if err := tw.SaveTask(saved); err != nil {
+		t.Fatalf("SaveTask: %v", err)
+	}
+	if saved.Status != "pending" || saved.Entry == 0 || saved.Modified == 0 {
+		t.Errorf("SaveTask left status %q, entry %d, modified %d", saved.Status, saved.Entry, saved.Modified)
+	}
+
+	tasks, err := tw.LoadPendingTasks()
+	if err != nil {
+		t.Fatalf("LoadPendingTasks: %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("got %d tasks, want 1", len(tasks))
+	}
+
+	loaded := tasks[0]
+	if loaded.ID != 1 {
+		t.Errorf("ID = %d, want 1", loaded.ID)
+	}
+	loaded.ID = 0
+	if !reflect.DeepEqual(loaded, saved) {
+		t.Errorf("loaded task differs from saved one:\n got %+v\nwant %+v", loaded, saved)
+	}
+}
+
+func TestSaveTaskUpdatesInPlace(t *testing.T) {
+	tw, fake := newFake(t)
+
+	task := &taskwarrior.Task{Description: "Draft"}
+	if err := tw.SaveTask(task); err != nil {
+		t.Fatalf("SaveTask: %v", err)
+	}
+	task.Description = "Final"
+	task.Status = "completed"
+	if err := tw.SaveTask(task); err != nil {
+		t.Fatalf("SaveTask: %v", err)
+	}
+	if task.End == 0 {
+		t.Error("completing a task did not set End")
+	}
+
+	stored, err := fake.Tasks()
+	if err != nil {
+		t.Fatalf("Tasks: %v", err)
+	}
+	if len(stored) != 1 || stored[0].Description != "Final" || stored[0].Status != "completed" {
+		t.Errorf("stored %+v, want the one task completed as Final", stored)
+	}
+
+	completed, err := tw.LoadCompletedTasks()
+	if err != nil {
+		t.Fatalf("LoadCompletedTasks: %v", err)
+	}
+	if len(completed) != 1 || completed[0].UUID != task.UUID {
+		t.Errorf("LoadCompletedTasks = %+v, want %s", completed, task.UUID)
+	}
+}
+
+func TestSaveTaskSetsUUIDBeforeImport(t *testing.T) {
+	var imported []*taskwarrior.Task
+	runner := runnerFunc(func(ctx context.Context, input []byte, args ...string) ([]byte, error) {
+		if !slices.Contains(args, "import") {
+			t.Fatalf("unexpected command %v", args)
+		}
+		var tasks []*taskwarrior.Task
+		if err := json.Unmarshal(input, &tasks); err != nil {
+			t.Fatalf("import payload: %v", err)
+		}
+		imported = append(imported, tasks...)
+		return nil, nil
+	})
+	tw, err := taskwarrior.New(taskwarrior.WithDataDir(t.TempDir()), taskwarrior.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	task := &taskwarrior.Task{Description: "New"}
+	if err := tw.SaveTask(task); err != nil {
+		t.Fatalf("SaveTask: %v", err)
+	}
+	if task.UUID == "" {
+		t.Fatal("SaveTask did not assign a UUID")
+	}
+	if len(imported) != 1 || imported[0].UUID != task.UUID {
+		t.Errorf("imported %+v, want the task under UUID %s", imported, task.UUID)
+	}
+
+	other := &taskwarrior.Task{Description: "Other"}
+	if err := tw.SaveTask(other); err != nil {
+		t.Fatalf("SaveTask: %v", err)
+	}
+	if other.UUID == task.UUID {
+		t.Errorf("two tasks were given UUID %s", task.UUID)
+	}
+}
+
+func TestDeleteTask(t *testing.T) {
+	tw, fake := newFake(t)
+
+	keep := &taskwarrior.Task{Description: "Keep"}
+	drop := &taskwarrior.Task{Description: "Drop"}
+	if err := tw.SaveTasksContext(context.Background(), []*taskwarrior.Task{keep, drop}); err != nil {
+		t.Fatalf("SaveTasksContext: %v", err)
+	}
+
+	if err := tw.DeleteTask(drop.UUID); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+
+	pending, err := tw.LoadPendingTasks()
+	if err != nil {
+		t.Fatalf("LoadPendingTasks: %v", err)
+	}
+	if len(pending) != 1 || pending[0].UUID != keep.UUID {
+		t.Errorf("pending = %+v, want only %s", pending, keep.UUID)
+	}
+
+	stored, err := fake.Tasks()
+	if err != nil {
+		t.Fatalf("Tasks: %v", err)
+	}
+	for _, task := range stored {
+		if task.UUID == drop.UUID && task.Status != "deleted" {
+			t.Errorf("deleted task has status %q", task.Status)
+		}
+	}
+}
+
+func TestDeleteTasksBatchError(t *testing.T) {
+	tw, _ := newFake(t)
+
+	uuids := []string{"00000000-0000-4000-8000-000000000001", "00000000-0000-4000-8000-000000000002"}
+	err := tw.DeleteTasksContext(context.Background(), uuids)
+
+	var batchErr *taskwarrior.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("error = %v, want a *BatchError", err)
+	}
+	for _, uuid := range uuids {
+		if _, ok := batchErr.Failed[uuid]; !ok {
+			t.Errorf("BatchError does not name %s", uuid)
+		}
+	}
+	var exitErr *taskwarrior.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Errorf("BatchError does not wrap an *ExitError: %v", err)
+	}
+}
+
+func TestSaveTasksImportsEachTaskAfterBatchFails(t *testing.T) {
+	_, fake := newFake(t)
+	rejected := errors.New("rejected")
+	runner := runnerFunc(func(ctx context.Context, input []byte, args ...string) ([]byte, error) {
+		var tasks []*taskwarrior.Task
+		if err := json.Unmarshal(input, &tasks); err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			if task.Description == "bad" {
+				return nil, rejected
+			}
+		}
+		return fake.Run(ctx, input, args...)
+	})
+	tw, err := taskwarrior.New(taskwarrior.WithDataDir(t.TempDir()), taskwarrior.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	good := &taskwarrior.Task{UUID: "00000000-0000-4000-8000-000000000001", Description: "good"}
+	bad := &taskwarrior.Task{UUID: "00000000-0000-4000-8000-000000000002", Description: "bad"}
+	err = tw.SaveTasksContext(context.Background(), []*taskwarrior.Task{good, bad})
+
+	var batchErr *taskwarrior.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("error = %v, want a *BatchError", err)
+	}
+	if len(batchErr.Failed) != 1 || !errors.Is(batchErr.Failed[bad.UUID], rejected) {
+		t.Errorf("Failed = %v, want only %s", batchErr.Failed, bad.UUID)
+	}
+
+	stored, err := fake.Tasks()
+	if err != nil {
+		t.Fatalf("Tasks: %v", err)
+	}
+	if len(stored) != 1 || stored[0].UUID != good.UUID {
+		t.Errorf("stored %d tasks, want only the good one", len(stored))
+	}
+}
+
+func TestLoadFallsBackOnlyWhenStoreIsMissing(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		file         string
+		content      string
+		wantErr      bool
+		wantExported bool
+	}{
+		{name: "no store", wantExported: true},
+		{name: "unrecognised data file", file: "pending.data", content: "not ff4\n", wantExported: true},
+		{name: "corrupt replica", file: "taskchampion.sqlite3", content: "not a database", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tc.file != "" {
+				if err := os.WriteFile(filepath.Join(dir, tc.file), []byte(tc.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			exported := false
+			runner := runnerFunc(func(ctx context.Context, input []byte, args ...string) ([]byte, error) {
+				exported = true
+				return []byte("[]"), nil
+			})
+			tw, err := taskwarrior.New(taskwarrior.WithDataDir(dir), taskwarrior.WithRunner(runner))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			_, err = tw.LoadPendingTasksContext(context.Background())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("error = %v, want error %v", err, tc.wantErr)
+			}
+			if exported != tc.wantExported {
+				t.Errorf("ran task export: %v, want %v", exported, tc.wantExported)
+			}
+		})
+	}
+}
+
+func TestExitError(t *testing.T) {
+	tw, _ := newFake(t)
+
+	err := tw.DeleteTask("00000000-0000-4000-8000-000000000001")
+
+	var exitErr *taskwarrior.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("error = %v, want an *ExitError", err)
+	}
+	if exitErr.Code == 0 || exitErr.Stderr == "" {
+		t.Errorf("ExitError = %+v, want a status and stderr", exitErr)
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	runner := runnerFunc(func(ctx context.Context, input []byte, args ...string) ([]byte, error) {
+		return []byte(`[{"uuid": `), nil
+	})
+	tw, err := taskwarrior.New(taskwarrior.WithDataDir(t.TempDir()), taskwarrior.WithRunner(runner))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	_, err = tw.LoadPendingTasks()
+	var decodeErr *taskwarrior.DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("error = %v, want a *DecodeError", err)
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	tw, _ := newFake(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := tw.LoadPendingTasksContext(ctx)
+	var timeoutErr *taskwarrior.TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("error = %v, want a *TimeoutError", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("TimeoutError does not wrap context.Canceled: %v", err)
+	}
+}
+
+func TestTimeoutErrorFromWithTimeout(t *testing.T) {
+	runner := runnerFunc(func(ctx context.Context, input []byte, args ...string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, &taskwarrior.TimeoutError{Args: args, Err: ctx.Err()}
+	})
+	tw, err := taskwarrior.New(
+		taskwarrior.WithDataDir(t.TempDir()),
+		taskwarrior.WithRunner(runner),
+		taskwarrior.WithTimeout(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	err = tw.SaveTask(&taskwarrior.Task{Description: "Stuck"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want the deadline to be exceeded", err)
+	}
+}
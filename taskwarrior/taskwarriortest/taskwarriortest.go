@@ -0,0 +1,330 @@
+// Package taskwarriortest provides an in-process fake of the `task` binary
+// for exercising taskwarrior.TaskWarrior without Taskwarrior installed.
+package taskwarriortest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EwanGreer/todolist/taskwarrior"
+)
+
+// Taskwarrior is a taskwarrior.Runner that keeps tasks in a JSON file under
+// its directory and understands the subset of the `task` command line used
+// by the taskwarrior package.
+type Taskwarrior struct {
+	mu   sync.Mutex
+	path string
+}
+
+var _ taskwarrior.Runner = (*Taskwarrior)(nil)
+
+func New(dir string) *Taskwarrior {
+	return &Taskwarrior{path: filepath.Join(dir, "fake-tasks.json")}
+}
+
+// NewTaskWarrior returns a TaskWarrior backed by a fake rooted at dir.
+func NewTaskWarrior(dir string) (*taskwarrior.TaskWarrior, *Taskwarrior, error) {
+	fake := New(dir)
+	tw, err := taskwarrior.New(taskwarrior.WithDataDir(dir), taskwarrior.WithRunner(fake))
+	if err != nil {
+		return nil, nil, err
+	}
+	return tw, fake, nil
+}
+
+// Tasks returns every stored task, including completed and deleted ones.
+func (f *Taskwarrior) Tasks() ([]*taskwarrior.Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.load()
+}
+
+func (f *Taskwarrior) Run(ctx context.Context, input []byte, args ...string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, &taskwarrior.TimeoutError{Args: args, Err: err}
+	}
+
+	var filter, mods []string
+	command := ""
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "rc."):
+		case command == "" && isCommand(arg):
+			command = arg
+		case command == "":
+			filter = append(filter, arg)
+		default:
+			mods = append(mods, arg)
+		}
+	}
+
+	tasks, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+
+	switch command {
+	case "export":
+		return f.export(tasks, filter)
+	case "import":
+		return f.importTasks(tasks, input)
+	case "add":
+		return f.add(tasks, mods)
+	case "modify", "done", "delete":
+		return f.update(tasks, command, filter, mods)
+	}
+	return nil, exitError(args, 2, "unsupported command")
+}
+
+func isCommand(arg string) bool {
+	switch arg {
+	case "export", "import", "add", "modify", "done", "delete":
+		return true
+	}
+	return false
+}
+
+func (f *Taskwarrior) export(tasks []*taskwarrior.Task, filter []string) ([]byte, error) {
+	var out []map[string]any
+	for _, task := range tasks {
+		data, err := exportFields(task)
+		if err != nil {
+			return nil, err
+		}
+		if !matches(task, data, filter) {
+			continue
+		}
+		if task.ID != 0 {
+			data["id"] = task.ID
+		}
+		out = append(out, data)
+	}
+	if out == nil {
+		out = []map[string]any{}
+	}
+	return json.Marshal(out)
+}
+
+func (f *Taskwarrior) importTasks(tasks []*taskwarrior.Task, input []byte) ([]byte, error) {
+	var imported []*taskwarrior.Task
+	if err := json.Unmarshal(input, &imported); err != nil {
+		return nil, exitError([]string{"import"}, 2, err.Error())
+	}
+
+	for _, task := range imported {
+		if task.UUID == "" {
+			return nil, exitError([]string{"import"}, 2, "a task is missing a uuid")
+		}
+		replaced := false
+		for i, existing := range tasks {
+			if existing.UUID == task.UUID {
+				tasks[i] = task
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			tasks = append(tasks, task)
+		}
+	}
+
+	if err := f.save(tasks); err != nil {
+		return nil, err
+	}
+	return fmt.Appendf(nil, "Imported %d tasks.\n", len(imported)), nil
+}
+
+func (f *Taskwarrior) add(tasks []*taskwarrior.Task, mods []string) ([]byte, error) {
+	now := time.Now().Unix()
+	task := &taskwarrior.Task{
+		UUID:     fmt.Sprintf("00000000-0000-4000-8000-%012d", len(tasks)+1),
+		Status:   "pending",
+		Entry:    now,
+		Modified: now,
+	}
+	applyModifications(task, mods)
+	if task.Description == "" {
+		return nil, exitError(mods, 2, "additional text must be provided")
+	}
+
+	tasks = append(tasks, task)
+	if err := f.save(tasks); err != nil {
+		return nil, err
+	}
+	return fmt.Appendf(nil, "Created task %d.\n", task.ID), nil
+}
+
+func (f *Taskwarrior) update(tasks []*taskwarrior.Task, command string, filter, mods []string) ([]byte, error) {
+	if len(filter) == 0 {
+		return nil, exitError([]string{command}, 2, "command requires a filter")
+	}
+
+	count := 0
+	now := time.Now().Unix()
+	for _, task := range tasks {
+		data, err := exportFields(task)
+		if err != nil {
+			return nil, err
+		}
+		if !matches(task, data, filter) {
+			continue
+		}
+
+		switch command {
+		case "modify":
+			applyModifications(task, mods)
+		case "done":
+			if task.Status != "pending" {
+				return nil, exitError([]string{command}, 1, "task is neither pending nor waiting")
+			}
+			task.Status = "completed"
+			task.End = now
+		case "delete":
+			task.Status = "deleted"
+			task.End = now
+		}
+		task.Modified = now
+		count++
+	}
+
+	if count == 0 {
+		return nil, exitError(filter, 1, "No tasks specified.")
+	}
+	if err := f.save(tasks); err != nil {
+		return nil, err
+	}
+	return fmt.Appendf(nil, "%s %d tasks.\n", command, count), nil
+}
+
+func applyModifications(task *taskwarrior.Task, mods []string) {
+	var words []string
+	for _, mod := range mods {
+		switch {
+		case strings.HasPrefix(mod, "+"):
+			if !task.HasTag(mod[1:]) {
+				task.Tags = append(task.Tags, mod[1:])
+			}
+		case strings.HasPrefix(mod, "-") && len(mod) > 1:
+			tags := task.Tags[:0]
+			for _, tag := range task.Tags {
+				if tag != mod[1:] {
+					tags = append(tags, tag)
+				}
+			}
+			task.Tags = tags
+		case strings.HasPrefix(mod, "project:"):
+			task.Project = strings.TrimPrefix(mod, "project:")
+		case strings.HasPrefix(mod, "priority:"):
+			task.Priority = strings.TrimPrefix(mod, "priority:")
+		case strings.HasPrefix(mod, "status:"):
+			task.Status = strings.TrimPrefix(mod, "status:")
+		default:
+			words = append(words, mod)
+		}
+	}
+	if len(words) > 0 {
+		task.Description = strings.Join(words, " ")
+	}
+}
+
+// matches reports whether task satisfies the filter. Terms may be +tag or
+// name:value compared against exported fields, which must all match, or
+// bare UUIDs and IDs, of which any one must match.
+func matches(task *taskwarrior.Task, data map[string]any, filter []string) bool {
+	identified := false
+	identifiers := 0
+	for _, term := range filter {
+		if strings.HasPrefix(term, "+") {
+			if !task.HasTag(term[1:]) {
+				return false
+			}
+			continue
+		}
+		if name, value, ok := strings.Cut(term, ":"); ok {
+			if fmt.Sprint(data[name]) != value {
+				return false
+			}
+			continue
+		}
+
+		identifiers++
+		if id, err := strconv.Atoi(term); err == nil {
+			identified = identified || task.ID == id
+		} else {
+			identified = identified || task.UUID == term
+		}
+	}
+	return identifiers == 0 || identified
+}
+
+func exportFields(task *taskwarrior.Task) (map[string]any, error) {
+	raw, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (f *Taskwarrior) load() ([]*taskwarrior.Task, error) {
+	raw, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []*taskwarrior.Task
+	if err := json.Unmarshal(raw, &tasks); err != nil {
+		return nil, err
+	}
+	renumber(tasks)
+	return tasks, nil
+}
+
+func (f *Taskwarrior) save(tasks []*taskwarrior.Task) error {
+	renumber(tasks)
+	raw, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.path, raw, 0o644)
+}
+
+// renumber assigns working-set IDs to pending tasks in entry order, the
+// way Taskwarrior does after a garbage collection.
+func renumber(tasks []*taskwarrior.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Entry < tasks[j].Entry
+	})
+
+	id := 0
+	for _, task := range tasks {
+		task.ID = 0
+		switch task.Status {
+		case "pending", "waiting", "recurring":
+			id++
+			task.ID = id
+		}
+	}
+}
+
+func exitError(args []string, code int, stderr string) error {
+	return &taskwarrior.ExitError{Args: args, Code: code, Stderr: stderr}
+}
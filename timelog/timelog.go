@@ -0,0 +1,220 @@
+// Package timelog records the intervals spent working on tasks in a sidecar
+// file kept in the Taskwarrior data directory, and totals them per task,
+// project and day.
+package timelog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the log inside the data directory. Taskwarrior
+// ignores files it does not know about.
+const FileName = "todolist-time.jsonl"
+
+// Entry is one interval of work on a task.
+type Entry struct {
+	UUID        string    `json:"uuid"`
+	Description string    `json:"description"`
+	Project     string    `json:"project,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+func (e Entry) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+// Log is an append-only file of entries, one JSON object per line. It is
+// safe for concurrent use within one process.
+type Log struct {
+	path string
+
+	// mu makes Append's read of the last entries and its write one step
+	mu sync.Mutex
+}
+
+// New returns the log kept in the data directory dir.
+func New(dir string) *Log {
+	return &Log{path: filepath.Join(dir, FileName)}
+}
+
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Entries reads every entry in the log. A log that does not exist yet is
+// empty.
+func (l *Log) Entries() ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.entries()
+}
+
+func (l *Log) entries() ([]Entry, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("timelog: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("timelog: %s line %d: %w", l.path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("timelog: %w", err)
+	}
+	return entries, nil
+}
+
+// Append adds entries to the log. An entry that overlaps the last one
+// recorded for its task is trimmed to start where that one ended, so a
+// start that is undone and stopped again is not counted twice.
+func (l *Log) Append(entries ...Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	existing, err := l.entries()
+	if err != nil {
+		return err
+	}
+	lastEnd := make(map[string]time.Time)
+	for _, entry := range existing {
+		if entry.End.After(lastEnd[entry.UUID]) {
+			lastEnd[entry.UUID] = entry.End
+		}
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("timelog: %w", err)
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	for _, entry := range entries {
+		if end, ok := lastEnd[entry.UUID]; ok && entry.Start.Before(end) {
+			entry.Start = end
+		}
+		if !entry.End.After(entry.Start) {
+			continue
+		}
+
+		entry.Start, entry.End = entry.Start.UTC(), entry.End.UTC()
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("timelog: %w", err)
+		}
+		lastEnd[entry.UUID] = entry.End
+	}
+	return nil
+}
+
+// Total is the time spent on one task, project or day.
+type Total struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Summary totals the time in a range. Tasks and projects are listed
+// longest first and days in date order.
+type Summary struct {
+	Tasks    []Total
+	Projects []Total
+	Days     []Total
+	Total    time.Duration
+}
+
+// Summarize totals the parts of entries that fall between from and to.
+// Intervals spanning midnight are split between the days, in loc.
+func Summarize(entries []Entry, from, to time.Time, loc *time.Location) Summary {
+	tasks := make(map[string]time.Duration)
+	projects := make(map[string]time.Duration)
+	days := make(map[string]time.Duration)
+	names := make(map[string]string)
+
+	var summary Summary
+	for _, entry := range entries {
+		start, end := entry.Start, entry.End
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		d := end.Sub(start)
+		summary.Total += d
+		tasks[entry.UUID] += d
+		names[entry.UUID] = entry.Description
+		project := entry.Project
+		if project == "" {
+			project = "(none)"
+		}
+		projects[project] += d
+
+		for day := start.In(loc); day.Before(end); {
+			next := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc)
+			if next.After(end) {
+				next = end
+			}
+			days[day.Format("2006-01-02 Mon")] += next.Sub(day)
+			day = next
+		}
+	}
+
+	for uuid, d := range tasks {
+		summary.Tasks = append(summary.Tasks, Total{Name: names[uuid], Duration: d})
+	}
+	for project, d := range projects {
+		summary.Projects = append(summary.Projects, Total{Name: project, Duration: d})
+	}
+	for day, d := range days {
+		summary.Days = append(summary.Days, Total{Name: day, Duration: d})
+	}
+
+	longestFirst := func(totals []Total) {
+		sort.Slice(totals, func(i, j int) bool {
+			if totals[i].Duration != totals[j].Duration {
+				return totals[i].Duration > totals[j].Duration
+			}
+			return totals[i].Name < totals[j].Name
+		})
+	}
+	longestFirst(summary.Tasks)
+	longestFirst(summary.Projects)
+	sort.Slice(summary.Days, func(i, j int) bool {
+		return summary.Days[i].Name < summary.Days[j].Name
+	})
+	return summary
+}
+
+// FormatDuration renders d to the minute, as 45m or 3h05m.
+func FormatDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
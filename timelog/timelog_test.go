@@ -0,0 +1,212 @@
+package timelog_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/EwanGreer/todolist/timelog"
+)
+
+var base = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func at(hours float64) time.Time {
+	return base.Add(time.Duration(hours * float64(time.Hour)))
+}
+
+func entry(uuid, project string, start, end float64) timelog.Entry {
+	return timelog.Entry{UUID: uuid, Description: "task " + uuid, Project: project, Start: at(start), End: at(end)}
+}
+
+func TestAppend(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		entries [][]timelog.Entry
+		want    []timelog.Entry
+	}{
+		{
+			name:    "separate intervals",
+			entries: [][]timelog.Entry{{entry("a", "", 0, 1)}, {entry("a", "", 2, 3)}},
+			want:    []timelog.Entry{entry("a", "", 0, 1), entry("a", "", 2, 3)},
+		},
+		{
+			name:    "overlap is trimmed",
+			entries: [][]timelog.Entry{{entry("a", "", 0, 2)}, {entry("a", "", 1, 3)}},
+			want:    []timelog.Entry{entry("a", "", 0, 2), entry("a", "", 2, 3)},
+		},
+		{
+			name:    "covered interval is dropped",
+			entries: [][]timelog.Entry{{entry("a", "", 0, 3)}, {entry("a", "", 1, 2)}},
+			want:    []timelog.Entry{entry("a", "", 0, 3)},
+		},
+		{
+			name:    "overlap within one call is trimmed",
+			entries: [][]timelog.Entry{{entry("a", "", 0, 2), entry("a", "", 1, 3)}},
+			want:    []timelog.Entry{entry("a", "", 0, 2), entry("a", "", 2, 3)},
+		},
+		{
+			name:    "other tasks may overlap",
+			entries: [][]timelog.Entry{{entry("a", "", 0, 2)}, {entry("b", "", 1, 3)}},
+			want:    []timelog.Entry{entry("a", "", 0, 2), entry("b", "", 1, 3)},
+		},
+		{
+			name:    "empty interval is dropped",
+			entries: [][]timelog.Entry{{entry("a", "", 1, 1)}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			log := timelog.New(t.TempDir())
+			for _, entries := range tc.entries {
+				if err := log.Append(entries...); err != nil {
+					t.Fatalf("Append: %v", err)
+				}
+			}
+
+			got, err := log.Entries()
+			if err != nil {
+				t.Fatalf("Entries: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d entries, want %d: %+v", len(got), len(tc.want), got)
+			}
+			for i := range got {
+				if got[i].UUID != tc.want[i].UUID || !got[i].Start.Equal(tc.want[i].Start) || !got[i].End.Equal(tc.want[i].End) {
+					t.Errorf("entry %d = %+v, want %+v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAppendConcurrent(t *testing.T) {
+	log := timelog.New(t.TempDir())
+
+	// Every append overlaps the others, so only the first may be kept whole
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if err := log.Append(entry("a", "", 0, 1)); err != nil {
+				t.Errorf("Append: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	entries, err := log.Entries()
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d entries for one hour of work, want 1", len(entries))
+	}
+}
+
+func TestEntriesMissingLog(t *testing.T) {
+	entries, err := timelog.New(t.TempDir()).Entries()
+	if err != nil || entries != nil {
+		t.Errorf("Entries() = %v, %v, want nothing", entries, err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	entries := []timelog.Entry{
+		entry("a", "work", 0, 2),
+		entry("b", "work", 3, 4),
+		entry("c", "", 5, 5.5),
+		// 22:00 to 01:00, split across two days
+		entry("a", "work", 13, 16),
+	}
+
+	for _, tc := range []struct {
+		name     string
+		from, to time.Time
+		total    time.Duration
+		tasks    []timelog.Total
+		projects []timelog.Total
+		days     []timelog.Total
+	}{
+		{
+			name:  "everything",
+			from:  at(-24),
+			to:    at(48),
+			total: 6*time.Hour + 30*time.Minute,
+			tasks: []timelog.Total{
+				{Name: "task a", Duration: 5 * time.Hour},
+				{Name: "task b", Duration: time.Hour},
+				{Name: "task c", Duration: 30 * time.Minute},
+			},
+			projects: []timelog.Total{
+				{Name: "work", Duration: 6 * time.Hour},
+				{Name: "(none)", Duration: 30 * time.Minute},
+			},
+			days: []timelog.Total{
+				{Name: "2026-03-02 Mon", Duration: 5*time.Hour + 30*time.Minute},
+				{Name: "2026-03-03 Tue", Duration: time.Hour},
+			},
+		},
+		{
+			name:  "clipped to the range",
+			from:  at(1),
+			to:    at(3.5),
+			total: 90 * time.Minute,
+			tasks: []timelog.Total{
+				{Name: "task a", Duration: time.Hour},
+				{Name: "task b", Duration: 30 * time.Minute},
+			},
+			projects: []timelog.Total{
+				{Name: "work", Duration: 90 * time.Minute},
+			},
+			days: []timelog.Total{
+				{Name: "2026-03-02 Mon", Duration: 90 * time.Minute},
+			},
+		},
+		{
+			name: "nothing in range",
+			from: at(30),
+			to:   at(40),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			summary := timelog.Summarize(entries, tc.from, tc.to, time.UTC)
+			if summary.Total != tc.total {
+				t.Errorf("total = %v, want %v", summary.Total, tc.total)
+			}
+			checkTotals(t, "tasks", summary.Tasks, tc.tasks)
+			checkTotals(t, "projects", summary.Projects, tc.projects)
+			checkTotals(t, "days", summary.Days, tc.days)
+		})
+	}
+}
+
+func checkTotals(t *testing.T, kind string, got, want []timelog.Total) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", kind, got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%s = %v, want %v", kind, got, want)
+			return
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for _, tc := range []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0m"},
+		{29 * time.Second, "0m"},
+		{30 * time.Second, "1m"},
+		{45 * time.Minute, "45m"},
+		{59*time.Minute + 40*time.Second, "1h00m"},
+		{3*time.Hour + 5*time.Minute, "3h05m"},
+		{26 * time.Hour, "26h00m"},
+	} {
+		if got := timelog.FormatDuration(tc.d); got != tc.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tc.d, got, tc.want)
+		}
+	}
+}